@@ -10,6 +10,8 @@ type Car struct{
 	Make string   //Car Manufacturer
 	Color string  //Car Color
 	Size  CarSize   // Size category of the car, use case- 10
+	Type  VehicleType // Kind of vehicle, see Vehicle; the zero value is a car
+	IsElectric bool   // Placed in charging slots where possible
 }
 
 //use case-10
@@ -0,0 +1,325 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Charging errors
+var (
+	ErrChargerBusy = errors.New("charger is already in use")
+	ErrNotCharging = errors.New("vehicle is not charging")
+)
+
+// Charger meters the energy delivered at charging slots. A lot starts a session when an
+// electric vehicle parks in a charging slot and stops it when the vehicle is unplugged or
+// leaves. Slots are identified by lot ID and slot ID, so one charger can serve several lots,
+// e.g. every level of a garage. Lots call the charger while holding their own lock, so
+// implementations must not call back into the lot.
+type Charger interface {
+	Start(lotID string, slotID int, at time.Time) error
+	Meter(lotID string, slotID int, at time.Time) float64 // kWh delivered by the slot's session up to at
+	Stop(lotID string, slotID int, at time.Time)
+}
+
+// chargePoint identifies a charging slot across lots
+type chargePoint struct {
+	lotID  string
+	slotID int
+}
+
+// SimulatedCharger delivers energy at a constant power until the battery is full. It stands
+// in for real charging hardware in tests and simulations and is safe for concurrent use.
+type SimulatedCharger struct {
+	mu      sync.Mutex
+	powerKW float64
+	maxKWh  float64
+	started map[chargePoint]time.Time // Session start by lot and slot
+}
+
+// NewSimulatedCharger creates a charger delivering powerKW per slot, stopping once maxKWh
+// have been delivered; 0 means no limit
+func NewSimulatedCharger(powerKW, maxKWh float64) *SimulatedCharger {
+	return &SimulatedCharger{powerKW: powerKW, maxKWh: maxKWh, started: make(map[chargePoint]time.Time)}
+}
+
+// Start implements Charger
+func (c *SimulatedCharger) Start(lotID string, slotID int, at time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	point := chargePoint{lotID: lotID, slotID: slotID}
+	if _, busy := c.started[point]; busy {
+		return fmt.Errorf("%w: %s slot %d", ErrChargerBusy, lotID, slotID)
+	}
+	c.started[point] = at
+	return nil
+}
+
+// Meter implements Charger
+func (c *SimulatedCharger) Meter(lotID string, slotID int, at time.Time) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	started, charging := c.started[chargePoint{lotID: lotID, slotID: slotID}]
+	if !charging || !at.After(started) {
+		return 0
+	}
+	delivered := c.powerKW * at.Sub(started).Hours()
+	if c.maxKWh > 0 && delivered > c.maxKWh {
+		return c.maxKWh
+	}
+	return delivered
+}
+
+// Stop implements Charger
+func (c *SimulatedCharger) Stop(lotID string, slotID int, at time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.started, chargePoint{lotID: lotID, slotID: slotID})
+}
+
+// ChargingSession is the energy delivered to one vehicle during a stay
+type ChargingSession struct {
+	Plate     string    `json:"plate"`
+	SlotID    int       `json:"slot_id"`
+	StartedAt time.Time `json:"started_at"`
+	EndedAt   time.Time `json:"ended_at,omitempty"` // Zero while the vehicle is still charging
+	KWh       float64   `json:"kwh,omitempty"`
+	Fault     string    `json:"fault,omitempty"` // Why the charger failed to start, empty if it started
+}
+
+// Active reports whether the vehicle is still charging
+func (s ChargingSession) Active() bool {
+	return s.EndedAt.IsZero()
+}
+
+// WithChargingSlots fits chargers to the given slots. Electric vehicles are placed in them
+// first and other vehicles only once nothing else fits.
+func WithChargingSlots(slotIDs ...int) LotOption {
+	return func(p *ParkingLot) {
+		for _, id := range slotIDs {
+			if id >= 0 && id < len(p.slots) && !p.slots[id].Charging {
+				p.slots[id].Charging = true
+				p.chargingSlots++
+			}
+		}
+	}
+}
+
+// WithCharger meters charging sessions with the given charger. Sessions of parked vehicles are
+// kept in snapshots and the journal, and a restored lot meters them on its charger, which must
+// be the one they were started on. Sessions left running at the lot's charging slots for
+// vehicles the restored lot does not know are stopped.
+func WithCharger(charger Charger) LotOption {
+	return func(p *ParkingLot) {
+		p.charger = charger
+	}
+}
+
+// GetAvailableChargingSpaces returns the number of free charging slots
+func (p *ParkingLot) GetAvailableChargingSpaces() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	free := 0
+	for _, slot := range p.slots {
+		if slot.Charging && !slot.IsOccupied() && slot.reservation == "" {
+			free++
+		}
+	}
+	return free
+}
+
+// CanCharge reports whether the vehicle could park in a free charging slot now
+func (p *ParkingLot) CanCharge(vehicle Vehicle) bool {
+	if vehicle == nil {
+		return false
+	}
+	car := vehicle.Record()
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for _, slot := range p.slots {
+		if slot.Charging && !slot.Accessible && !slot.IsOccupied() && slot.reservation == "" && slot.Size.accepts(car) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetChargingSession returns the charging session of a parked vehicle, with the energy
+// delivered so far if it is still charging
+func (p *ParkingLot) GetChargingSession(plateNumber string) (ChargingSession, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	session, exists := p.charging[NormalizePlate(plateNumber)]
+	if !exists {
+		return ChargingSession{}, false
+	}
+	current := *session
+	if current.Active() {
+		current.KWh = p.charger.Meter(p.id, current.SlotID, p.clock.Now())
+	}
+	return current, true
+}
+
+// GetChargingSessions returns the sessions of vehicles that have left, in the order they left
+func (p *ParkingLot) GetChargingSessions() []ChargingSession {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	sessions := make([]ChargingSession, len(p.chargingLog))
+	copy(sessions, p.chargingLog)
+	return sessions
+}
+
+// StopCharging unplugs a parked vehicle, e.g. when its battery is full. The vehicle stays
+// parked and the energy is billed when it leaves.
+func (p *ParkingLot) StopCharging(plateNumber string) (ChargingSession, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	plateNumber = NormalizePlate(plateNumber)
+	session, exists := p.charging[plateNumber]
+	if !exists || !session.Active() {
+		return ChargingSession{}, fmt.Errorf("%w: %s", ErrNotCharging, plateNumber)
+	}
+	now := p.clock.Now()
+	stopped := p.endedSession(plateNumber, now)
+	if err := p.writeJournal(JournalRecord{Type: JournalChargingStopped, LotID: p.id, Time: now, SlotID: session.SlotID, Charging: stopped}); err != nil {
+		return ChargingSession{}, err
+	}
+	p.stopSession(session, now)
+	return *session, nil
+}
+
+// startCharging opens a session for an electric vehicle about to park in a charging slot, or
+// returns nil if it will not charge. A charger that fails to start gives an ended session
+// recording the fault, so the failure shows in GetChargingSession and GetChargingSessions. The
+// caller must hold p.mu for writing.
+func (p *ParkingLot) startCharging(slot *Slot, info CarParkingInfo, at time.Time) *ChargingSession {
+	if p.charger == nil || !slot.Charging || !info.Car.IsElectric {
+		return nil
+	}
+	session := &ChargingSession{Plate: info.Car.Plate, SlotID: slot.ID, StartedAt: at}
+	if err := p.charger.Start(p.id, slot.ID, at); err != nil {
+		session.EndedAt = at
+		session.Fault = err.Error()
+	}
+	return session
+}
+
+// cancelCharging stops a session opened for a vehicle that did not park after all. The caller
+// must hold p.mu for writing.
+func (p *ParkingLot) cancelCharging(session *ChargingSession, at time.Time) {
+	if session != nil && session.Active() {
+		p.charger.Stop(p.id, session.SlotID, at)
+	}
+}
+
+// adoptCharging takes over the recorded session of a restored or replayed vehicle without
+// calling the charger, which is still running it. A lot without a charger cannot meter the
+// session, so it is ended at. The caller must hold p.mu for writing.
+func (p *ParkingLot) adoptCharging(session ChargingSession, at time.Time) {
+	if p.charger == nil && session.Active() {
+		session.EndedAt = at
+	}
+	p.charging[session.Plate] = &session
+}
+
+// retireCharging files the session of a replayed departure as recorded, without calling the
+// charger. The caller must hold p.mu for writing.
+func (p *ParkingLot) retireCharging(plateNumber string, recorded *ChargingSession, at time.Time) {
+	session, exists := p.charging[plateNumber]
+	if !exists {
+		return
+	}
+	ended := *session
+	if recorded != nil {
+		ended = *recorded
+	}
+	if ended.Active() {
+		ended.EndedAt = at
+	}
+	delete(p.charging, plateNumber)
+	p.chargingLog = append(p.chargingLog, ended)
+}
+
+// stopOrphanedCharging stops charger sessions at the lot's charging slots that no parked
+// vehicle is charging from, e.g. one started for a car parked after the snapshot the lot was
+// restored from
+func (p *ParkingLot) stopOrphanedCharging() {
+	if p.charger == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	charging := make(map[int]bool, len(p.charging))
+	for _, session := range p.charging {
+		if session.Active() {
+			charging[session.SlotID] = true
+		}
+	}
+	now := p.clock.Now()
+	for _, slot := range p.slots {
+		if slot.Charging && !charging[slot.ID] {
+			p.charger.Stop(p.id, slot.ID, now)
+		}
+	}
+}
+
+// chargingState returns a copy of the session of a parked vehicle, or nil if it has none. The
+// caller must hold p.mu.
+func (p *ParkingLot) chargingState(plateNumber string) *ChargingSession {
+	session, exists := p.charging[plateNumber]
+	if !exists {
+		return nil
+	}
+	state := *session
+	return &state
+}
+
+// endedSession returns the session of a parked vehicle as it would stand if it ended at at,
+// or nil if the vehicle has none. The caller must hold p.mu.
+func (p *ParkingLot) endedSession(plateNumber string, at time.Time) *ChargingSession {
+	session, exists := p.charging[plateNumber]
+	if !exists {
+		return nil
+	}
+	ended := *session
+	if ended.Active() {
+		ended.KWh = p.charger.Meter(p.id, ended.SlotID, at)
+		ended.EndedAt = at
+	}
+	return &ended
+}
+
+// chargedEnergy returns the kWh delivered to a parked vehicle up to at. The caller must hold p.mu.
+func (p *ParkingLot) chargedEnergy(plateNumber string, at time.Time) float64 {
+	session, exists := p.charging[plateNumber]
+	if !exists {
+		return 0
+	}
+	if session.Active() {
+		return p.charger.Meter(p.id, session.SlotID, at)
+	}
+	return session.KWh
+}
+
+// endCharging closes the session of a departing vehicle. The caller must hold p.mu for writing.
+func (p *ParkingLot) endCharging(plateNumber string, at time.Time) {
+	session, exists := p.charging[plateNumber]
+	if !exists {
+		return
+	}
+	if session.Active() {
+		p.stopSession(session, at)
+	}
+	delete(p.charging, plateNumber)
+	p.chargingLog = append(p.chargingLog, *session)
+}
+
+// stopSession meters and stops an active session. The caller must hold p.mu for writing.
+func (p *ParkingLot) stopSession(session *ChargingSession, at time.Time) {
+	session.KWh = p.charger.Meter(p.id, session.SlotID, at)
+	session.EndedAt = at
+	p.charger.Stop(p.id, session.SlotID, at)
+}
@@ -0,0 +1,21 @@
+package domain
+
+import "errors"
+
+// Sentinel errors returned by the error-returning parking operations.
+// Callers can tell them apart with errors.Is.
+var (
+	ErrLotFull          = errors.New("parking lot is full")
+	ErrCarAlreadyParked = errors.New("car is already parked")
+	ErrCarNotFound      = errors.New("car is not parked")
+	ErrNoLotsAvailable  = errors.New("no parking lots available")
+	ErrInvalidCar       = errors.New("invalid car")
+	ErrUnknownRow       = errors.New("row does not exist in this lot")
+)
+
+// Ticket errors returned by UnparkByTicket
+var (
+	ErrInvalidTicket     = errors.New("ticket was not issued by this lot")
+	ErrTicketAlreadyUsed = errors.New("ticket has already been used")
+	ErrTicketRequired    = errors.New("lot only releases cars against a ticket")
+)
@@ -0,0 +1,298 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnknownLevel is returned when a garage has no level with the requested number
+var ErrUnknownLevel = errors.New("level does not exist in this garage")
+
+// RowSpec describes one row of a level's layout
+type RowSpec struct {
+	Name  string
+	Slots int
+	Size  SlotSize // Size of every slot in the row; AnySize leaves sizes as they are
+}
+
+// rowRange is the contiguous run of slot IDs making up a row
+type rowRange struct {
+	name        string
+	first, last int // Slot IDs, inclusive
+}
+
+// WithRows lays the lot's slots out in rows, filling them in slot order: the first row gets
+// slots 0..n-1 and so on. Slots past the last row belong to no row, and rows are cut short
+// at the lot's capacity. A lot with a row layout parks ParkInRow cars in the named row and
+// records every car under the row of its slot.
+func WithRows(rows ...RowSpec) LotOption {
+	return func(p *ParkingLot) {
+		p.rows = nil
+		for _, slot := range p.slots {
+			slot.Row, slot.Number = "", 0
+		}
+		next := 0
+		for _, spec := range rows {
+			if spec.Slots <= 0 || next >= len(p.slots) {
+				continue
+			}
+			r := rowRange{name: spec.Name, first: next, last: min(next+spec.Slots, len(p.slots)) - 1}
+			for id := r.first; id <= r.last; id++ {
+				p.slots[id].Row = spec.Name
+				p.slots[id].Number = id - r.first + 1
+				if spec.Size != AnySize {
+					p.slots[id].Size = spec.Size
+				}
+			}
+			p.rows = append(p.rows, r)
+			next = r.last + 1
+		}
+	}
+}
+
+// slotNumber returns the slot's position within its row, as in Slot.Number, working it out
+// from the row layout so the slot itself need not be read
+func (p *ParkingLot) slotNumber(id int) int {
+	for _, r := range p.rows {
+		if id >= r.first && id <= r.last {
+			return id - r.first + 1
+		}
+	}
+	return 0
+}
+
+// GetRows returns the names of the lot's rows in slot order, or nil for free-text rows
+func (p *ParkingLot) GetRows() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	var names []string
+	for _, r := range p.rows {
+		names = append(names, r.name)
+	}
+	return names
+}
+
+// LevelSpec describes one level of a garage
+type LevelSpec struct {
+	Number int    // e.g. 2, or -1 for a basement
+	Name   string // Defaults to "Level <Number>"
+	Rows   []RowSpec
+}
+
+// Garage is a building of numbered levels. Each level is a ParkingLot whose capacity is the
+// sum of its rows.
+type Garage struct {
+	id     string
+	name   string
+	levels []*Level // In the order given to NewGarage
+}
+
+// Level is one floor of a garage
+type Level struct {
+	garage *Garage
+	number int
+	name   string
+	lot    *ParkingLot
+}
+
+// NewGarage builds a garage from its level layouts. Level lots get the ID "<id>-L<number>" and
+// the name "<name>, <level name>"; opts are applied to every level's lot, so a shared clock,
+// tariff or registry can be set once.
+func NewGarage(id, name string, levels []LevelSpec, opts ...LotOption) *Garage {
+	g := &Garage{id: id, name: name}
+	for _, spec := range levels {
+		capacity := 0
+		for _, row := range spec.Rows {
+			capacity += max(row.Slots, 0)
+		}
+		levelName := spec.Name
+		if levelName == "" {
+			levelName = fmt.Sprintf("Level %d", spec.Number)
+		}
+
+		level := &Level{garage: g, number: spec.Number, name: levelName}
+		levelOpts := append(append([]LotOption(nil), opts...),
+			WithRows(spec.Rows...),
+			WithID(fmt.Sprintf("%s-L%d", id, spec.Number)),
+			WithName(name+", "+levelName),
+		)
+		level.lot = NewParkingLot(capacity, levelOpts...)
+		level.lot.level = level
+		g.levels = append(g.levels, level)
+	}
+	return g
+}
+
+// GetID returns the garage's ID
+func (g *Garage) GetID() string {
+	return g.id
+}
+
+// GetName returns the garage's display name
+func (g *Garage) GetName() string {
+	return g.name
+}
+
+// GetLevels returns the garage's levels in the order they were given
+func (g *Garage) GetLevels() []*Level {
+	levels := make([]*Level, len(g.levels))
+	copy(levels, g.levels)
+	return levels
+}
+
+// GetLevel returns the level with the given number
+func (g *Garage) GetLevel(number int) (*Level, error) {
+	for _, level := range g.levels {
+		if level.number == number {
+			return level, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s has no level %d", ErrUnknownLevel, g.name, number)
+}
+
+// GetLots returns every level's lot, e.g. to hand a whole garage to a police investigation
+func (g *Garage) GetLots() []*ParkingLot {
+	lots := make([]*ParkingLot, len(g.levels))
+	for i, level := range g.levels {
+		lots[i] = level.lot
+	}
+	return lots
+}
+
+// GetCapacity returns the number of slots across all levels
+func (g *Garage) GetCapacity() int {
+	total := 0
+	for _, level := range g.levels {
+		total += level.GetCapacity()
+	}
+	return total
+}
+
+// GetAvailableSpaces returns the number of free slots across all levels
+func (g *Garage) GetAvailableSpaces() int {
+	total := 0
+	for _, level := range g.levels {
+		total += level.GetAvailableSpaces()
+	}
+	return total
+}
+
+// GetNumber returns the level's number
+func (l *Level) GetNumber() int {
+	return l.number
+}
+
+// GetName returns the level's display name
+func (l *Level) GetName() string {
+	return l.name
+}
+
+// GetGarage returns the garage the level belongs to
+func (l *Level) GetGarage() *Garage {
+	return l.garage
+}
+
+// GetLot returns the lot holding the level's slots
+func (l *Level) GetLot() *ParkingLot {
+	return l.lot
+}
+
+// GetCapacity returns the number of slots on the level
+func (l *Level) GetCapacity() int {
+	return len(l.lot.slots)
+}
+
+// GetAvailableSpaces returns the number of free slots on the level
+func (l *Level) GetAvailableSpaces() int {
+	return l.lot.GetAvailableSpaces()
+}
+
+// GetLevel returns the garage level the lot models, or nil for a standalone lot
+func (p *ParkingLot) GetLevel() *Level {
+	return p.level
+}
+
+// Location is where a car is parked, from the garage down to the slot
+type Location struct {
+	GarageID   string // Empty for a standalone lot
+	GarageName string
+	Level      int
+	LevelName  string
+	LotID      string
+	LotName    string
+	Row        string
+	SlotID     int // Slot ID within the lot
+	SlotNumber int // Position within the row, 0 without a row layout
+}
+
+// String describes the location the way it is signposted, e.g.
+// "Garage North, Level 2, Row B, Slot 14", or "Airport P1, Slot 3" for a standalone lot
+func (l Location) String() string {
+	var parts []string
+	if l.GarageID != "" {
+		parts = append(parts, l.GarageName, l.LevelName)
+	} else {
+		parts = append(parts, l.LotName)
+	}
+	if l.Row != "" {
+		parts = append(parts, "Row "+l.Row)
+	}
+	if l.SlotNumber > 0 {
+		parts = append(parts, fmt.Sprintf("Slot %d", l.SlotNumber))
+	} else {
+		parts = append(parts, fmt.Sprintf("Slot %d", l.SlotID))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Locate returns where the car with the given plate is parked
+func (p *ParkingLot) Locate(plateNumber string) (Location, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	info, exists := p.parkedInfo(NormalizePlate(plateNumber))
+	if !exists {
+		return Location{}, false
+	}
+	return p.locationOf(&info), true
+}
+
+// locationOf describes where a parked car is. Slot numbers and the lot's place in a garage
+// are fixed when the lot is built, so no lock is needed.
+func (p *ParkingLot) locationOf(info *CarParkingInfo) Location {
+	location := Location{
+		LotID:      p.id,
+		LotName:    p.GetName(),
+		Row:        info.Row,
+		SlotID:     info.SlotID,
+		SlotNumber: p.slotNumber(info.SlotID),
+	}
+	if p.level != nil {
+		location.GarageID = p.level.garage.id
+		location.GarageName = p.level.garage.name
+		location.Level = p.level.number
+		location.LevelName = p.level.name
+	}
+	return location
+}
+
+// ParkCarOnLevel parks a car on the given level of a garage, in row if one is named
+func (a *ParkingAttendant) ParkCarOnLevel(garage *Garage, levelNumber int, row string, car Car) (Ticket, error) {
+	level, err := garage.GetLevel(levelNumber)
+	if err != nil {
+		return Ticket{}, err
+	}
+	return level.lot.parkAndNotify(parkRequest{car: car, row: row, attendant: a.name})
+}
+
+// ParkCarInGarage parks a car on the first level, in the garage's order, with a free slot it fits
+func (a *ParkingAttendant) ParkCarInGarage(garage *Garage, car Car) (Ticket, error) {
+	return a.parkInChosenLot(garage.GetLots(), parkRequest{car: car}, func(lots []*ParkingLot) *ParkingLot {
+		for _, lot := range lots {
+			if lot.CanFitVehicle(car) {
+				return lot
+			}
+		}
+		return nil
+	})
+}
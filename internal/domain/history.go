@@ -0,0 +1,131 @@
+package domain
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Visit is a completed stay of a car in a lot, with the car's attributes normalized
+type Visit struct {
+	Plate             string
+	Make              string
+	Color             string
+	Size              CarSize
+	Type              VehicleType
+	LotID             string
+	SlotID            int
+	Row               string
+	IsHandicap        bool
+	EntryTime         time.Time
+	ExitTime          time.Time
+	AttendantName     string
+	ExitAttendantName string // Attendant who handed the car back, empty if the driver collected it
+}
+
+// Car returns the visiting car
+func (v Visit) Car() Car {
+	return Car{Plate: v.Plate, Make: v.Make, Color: v.Color, Size: v.Size, Type: v.Type}
+}
+
+// VisitFilter selects visits from a history store. Zero-valued fields match everything.
+type VisitFilter struct {
+	From  time.Time // Visits still present at or after From
+	To    time.Time // Visits that arrived at or before To
+	Plate string
+	Make  string
+	Color string
+	Size  *CarSize
+	Type  *VehicleType
+	LotID string
+}
+
+// matches reports whether the visit satisfies every field set in the filter
+func (f VisitFilter) matches(visit Visit) bool {
+	if !f.From.IsZero() && visit.ExitTime.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && visit.EntryTime.After(f.To) {
+		return false
+	}
+	if f.Plate != "" && visit.Plate != NormalizePlate(f.Plate) {
+		return false
+	}
+	if f.Make != "" && visit.Make != NormalizeMake(f.Make) {
+		return false
+	}
+	if f.Color != "" && visit.Color != NormalizeColor(f.Color) {
+		return false
+	}
+	if f.Size != nil && visit.Size != *f.Size {
+		return false
+	}
+	if f.Type != nil && visit.Type != *f.Type {
+		return false
+	}
+	if f.LotID != "" && visit.LotID != f.LotID {
+		return false
+	}
+	return true
+}
+
+// HistoryStore keeps completed visits. Lots call Record while holding their own lock,
+// so implementations must not call back into the lot.
+type HistoryStore interface {
+	Record(visit Visit)
+	Query(filter VisitFilter) []Visit
+}
+
+// InMemoryHistoryStore is a HistoryStore held in memory. It is safe for concurrent use
+// and can be shared by several lots.
+type InMemoryHistoryStore struct {
+	mu     sync.RWMutex
+	visits []Visit // Ordered by entry time
+}
+
+// NewInMemoryHistoryStore creates an empty history store
+func NewInMemoryHistoryStore() *InMemoryHistoryStore {
+	return &InMemoryHistoryStore{}
+}
+
+// Record stores a completed visit
+func (s *InMemoryHistoryStore) Record(visit Visit) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := sort.Search(len(s.visits), func(i int) bool {
+		return s.visits[i].EntryTime.After(visit.EntryTime)
+	})
+	s.visits = append(s.visits, Visit{})
+	copy(s.visits[i+1:], s.visits[i:])
+	s.visits[i] = visit
+}
+
+// Query returns the visits matching the filter ordered by entry time
+func (s *InMemoryHistoryStore) Query(filter VisitFilter) []Visit {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// Visits are ordered by entry time, so nothing after To can match
+	end := len(s.visits)
+	if !filter.To.IsZero() {
+		end = sort.Search(len(s.visits), func(i int) bool {
+			return s.visits[i].EntryTime.After(filter.To)
+		})
+	}
+
+	var matches []Visit
+	for _, visit := range s.visits[:end] {
+		if filter.matches(visit) {
+			matches = append(matches, visit)
+		}
+	}
+	return matches
+}
+
+// Len returns the number of visits stored
+func (s *InMemoryHistoryStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.visits)
+}
@@ -0,0 +1,214 @@
+package domain
+
+import (
+	"math/bits"
+	"strings"
+)
+
+// slotSet is a bitset of slot IDs. Iterating it visits slots in ascending order, so index
+// lookups come out in the same order as a scan. Sets returned by lookups are shared with the
+// index and must not be modified.
+type slotSet []uint64
+
+// with returns the set with id added
+func (s slotSet) with(id int) slotSet {
+	word := id / 64
+	for len(s) <= word {
+		s = append(s, 0)
+	}
+	s[word] |= 1 << (id % 64)
+	return s
+}
+
+// without removes id from the set
+func (s slotSet) without(id int) {
+	if word := id / 64; word < len(s) {
+		s[word] &^= 1 << (id % 64)
+	}
+}
+
+// isEmpty reports whether the set has no slots
+func (s slotSet) isEmpty() bool {
+	for _, w := range s {
+		if w != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// count returns the number of slots in the set
+func (s slotSet) count() int {
+	n := 0
+	for _, w := range s {
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
+
+// intersect returns the slots in both sets
+func (s slotSet) intersect(other slotSet) slotSet {
+	n := min(len(s), len(other))
+	result := make(slotSet, n)
+	for i := range result {
+		result[i] = s[i] & other[i]
+	}
+	return result
+}
+
+// union returns the slots in either set
+func (s slotSet) union(other slotSet) slotSet {
+	if len(s) < len(other) {
+		s, other = other, s
+	}
+	result := make(slotSet, len(s))
+	copy(result, s)
+	for i, w := range other {
+		result[i] |= w
+	}
+	return result
+}
+
+// intersectWith keeps only the slots also in other, reusing s. s must not be shared with the index.
+func (s slotSet) intersectWith(other slotSet) slotSet {
+	s = s[:min(len(s), len(other))]
+	for i := range s {
+		s[i] &= other[i]
+	}
+	return s
+}
+
+// unionWith adds the slots in other, reusing s. s must not be shared with the index.
+func (s slotSet) unionWith(other slotSet) slotSet {
+	for len(s) < len(other) {
+		s = append(s, 0)
+	}
+	for i, w := range other {
+		s[i] |= w
+	}
+	return s
+}
+
+// each calls fn for every slot ID in ascending order
+func (s slotSet) each(fn func(id int)) {
+	for i, w := range s {
+		for w != 0 {
+			bit := bits.TrailingZeros64(w)
+			fn(i*64 + bit)
+			w &^= 1 << bit
+		}
+	}
+}
+
+// carIndex maps attribute values to the slots of the parked cars that have them. It is
+// updated on every park and unpark so queries on indexed attributes avoid scanning the lot.
+// Plates themselves are already indexed by ParkingLot.slotByPlate.
+type carIndex struct {
+	byColor map[string]slotSet
+	byMake  map[string]slotSet
+	bySize  map[CarSize]slotSet
+	byType  map[VehicleType]slotSet
+	byRow   map[string]slotSet
+}
+
+func newCarIndex() *carIndex {
+	return &carIndex{
+		byColor: make(map[string]slotSet),
+		byMake:  make(map[string]slotSet),
+		bySize:  make(map[CarSize]slotSet),
+		byType:  make(map[VehicleType]slotSet),
+		byRow:   make(map[string]slotSet),
+	}
+}
+
+// add indexes a parked car
+func (idx *carIndex) add(info CarParkingInfo) {
+	addSlot(idx.byColor, info.Car.Color, info.SlotID)
+	addSlot(idx.byMake, info.Car.Make, info.SlotID)
+	addSlot(idx.bySize, info.Car.Size, info.SlotID)
+	addSlot(idx.byType, info.Car.Type, info.SlotID)
+	addSlot(idx.byRow, info.Row, info.SlotID)
+}
+
+// remove drops a car from the index
+func (idx *carIndex) remove(info CarParkingInfo) {
+	removeSlot(idx.byColor, info.Car.Color, info.SlotID)
+	removeSlot(idx.byMake, info.Car.Make, info.SlotID)
+	removeSlot(idx.bySize, info.Car.Size, info.SlotID)
+	removeSlot(idx.byType, info.Car.Type, info.SlotID)
+	removeSlot(idx.byRow, info.Row, info.SlotID)
+}
+
+func addSlot[K comparable](sets map[K]slotSet, key K, id int) {
+	sets[key] = sets[key].with(id)
+}
+
+func removeSlot[K comparable](sets map[K]slotSet, key K, id int) {
+	set := sets[key]
+	set.without(id)
+	if set.isEmpty() {
+		delete(sets, key)
+	}
+}
+
+// indexLookup returns the slots whose cars can possibly satisfy a predicate.
+// The caller must hold p.mu.
+type indexLookup func(p *ParkingLot) slotSet
+
+// lookupPlate narrows an exact plate pattern to at most one slot
+func lookupPlate(pattern string) indexLookup {
+	if strings.ContainsAny(pattern, `*?[\`) {
+		return nil // Patterns with wildcards need a scan
+	}
+	return func(p *ParkingLot) slotSet {
+		if slotID, parked := p.slotByPlate[pattern]; parked {
+			return slotSet(nil).with(slotID)
+		}
+		return nil
+	}
+}
+
+// lookupAll intersects the lookups that can be used, or returns nil if none can
+func lookupAll(lookups []indexLookup) indexLookup {
+	var usable []indexLookup
+	for _, lookup := range lookups {
+		if lookup != nil {
+			usable = append(usable, lookup)
+		}
+	}
+	switch len(usable) {
+	case 0:
+		return nil
+	case 1:
+		return usable[0]
+	}
+	return func(p *ParkingLot) slotSet {
+		result := usable[0](p).intersect(usable[1](p))
+		for _, lookup := range usable[2:] {
+			result = result.intersectWith(lookup(p))
+		}
+		return result
+	}
+}
+
+// lookupAny unions the lookups, or returns nil unless every lookup can be used
+func lookupAny(lookups []indexLookup) indexLookup {
+	if len(lookups) == 0 {
+		return nil
+	}
+	for _, lookup := range lookups {
+		if lookup == nil {
+			return nil
+		}
+	}
+	if len(lookups) == 1 {
+		return lookups[0]
+	}
+	return func(p *ParkingLot) slotSet {
+		result := lookups[0](p).union(lookups[1](p))
+		for _, lookup := range lookups[2:] {
+			result = result.unionWith(lookup(p))
+		}
+		return result
+	}
+}
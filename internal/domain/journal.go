@@ -0,0 +1,525 @@
+package domain
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Journal errors
+var (
+	ErrJournalCorrupt = errors.New("journal is corrupt or has been tampered with")
+	ErrJournalWrite   = errors.New("cannot write to journal")
+	ErrJournalInUse   = errors.New("journal belongs to another lot")
+)
+
+// JournalEventType names a state change recorded in the journal
+type JournalEventType string
+
+const (
+	JournalLotOpened      JournalEventType = "lot_opened"
+	JournalPark           JournalEventType = "park"
+	JournalUnpark         JournalEventType = "unpark"
+	JournalRowAssigned    JournalEventType = "row_assigned"
+	JournalLotFull        JournalEventType = "lot_full"
+	JournalSpaceAvailable JournalEventType = "space_available"
+
+	JournalReserved             JournalEventType = "reserved"
+	JournalReservationHeld      JournalEventType = "reservation_held"
+	JournalReservationNoShow    JournalEventType = "reservation_no_show"
+	JournalReservationCancelled JournalEventType = "reservation_cancelled"
+	JournalReservationHonoured  JournalEventType = "reservation_honoured"
+
+	JournalChargingStopped JournalEventType = "charging_stopped"
+)
+
+// JournalRecord is one state change of a lot
+type JournalRecord struct {
+	Seq           uint64           `json:"seq"`
+	Type          JournalEventType `json:"type"`
+	LotID         string           `json:"lot_id"`
+	LotName       string           `json:"lot_name,omitempty"` // Set on lot_opened records
+	Time          time.Time        `json:"time"`
+	Capacity      int              `json:"capacity,omitempty"`
+	Car           *Car             `json:"car,omitempty"`
+	EnteredAs     *Car             `json:"entered_as,omitempty"` // Car as typed, when it differs from Car
+	SlotID        int              `json:"slot_id"`
+	Row           string           `json:"row,omitempty"`
+	IsHandicap    bool             `json:"is_handicap,omitempty"`
+	AttendantName string           `json:"attendant,omitempty"`
+	Ticket        *Ticket          `json:"ticket,omitempty"`
+	PlateFlag     string           `json:"plate_flag,omitempty"`
+	SpansTwoSlots bool             `json:"spans_two_slots,omitempty"`
+	PermitFlag    string           `json:"permit_flag,omitempty"`
+	Reservation   *Reservation     `json:"reservation,omitempty"`    // Set on reserved records
+	ReservationID string           `json:"reservation_id,omitempty"` // Set on other reservation records
+	Charging      *ChargingSession `json:"charging,omitempty"`       // Vehicle's charging session, if any
+}
+
+// Journal is an append-only file of lot state changes. Each line holds a record and a SHA-256
+// hash chained to the previous line, so edited, removed or reordered records are detected on read.
+// It is safe for concurrent use.
+type Journal struct {
+	mu       sync.Mutex
+	file     *os.File
+	seq      uint64
+	lastHash string
+	lotID    string // Lot named by the lot_opened record, empty until one is written
+}
+
+// OpenJournal opens or creates the journal at path, verifying any records already in it
+func OpenJournal(path string) (*Journal, error) {
+	records, lastHash, validLength, err := readJournal(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	// Drop a torn final write so new records start on a clean line
+	if err := file.Truncate(validLength); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	journal := &Journal{file: file, lastHash: lastHash}
+	if len(records) > 0 {
+		journal.seq = records[len(records)-1].Seq
+		if records[0].Type == JournalLotOpened {
+			journal.lotID = records[0].LotID
+		}
+	}
+	return journal, nil
+}
+
+// Close closes the journal file
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.file.Close()
+}
+
+// Sync flushes written records to stable storage
+func (j *Journal) Sync() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.file.Sync()
+}
+
+// LastSeq returns the sequence number of the last record written
+func (j *Journal) LastSeq() uint64 {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.seq
+}
+
+// append numbers, chains and writes the records in a single write. On failure nothing is
+// considered written and the journal's sequence is left unchanged.
+func (j *Journal) append(records ...JournalRecord) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.write(records)
+}
+
+// claim starts a new journal with the opening records of the lot, or checks that a journal
+// already holding records was opened by it
+func (j *Journal) claim(lotID string, opening func() []JournalRecord) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.seq > 0 {
+		if j.lotID != lotID {
+			return fmt.Errorf("%w: opened by lot %q", ErrJournalInUse, j.lotID)
+		}
+		return nil
+	}
+	if err := j.write(opening()); err != nil {
+		return err
+	}
+	j.lotID = lotID
+	return nil
+}
+
+// write numbers, chains and writes the records like append. The caller must hold j.mu.
+func (j *Journal) write(records []JournalRecord) error {
+	var buf bytes.Buffer
+	seq, hash := j.seq, j.lastHash
+	for _, record := range records {
+		seq++
+		record.Seq = seq
+		payload, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrJournalWrite, err)
+		}
+		hash = chainHash(hash, payload)
+		buf.WriteString(hash)
+		buf.WriteByte(' ')
+		buf.Write(payload)
+		buf.WriteByte('\n')
+	}
+
+	if _, err := j.file.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("%w: %v", ErrJournalWrite, err)
+	}
+	j.seq, j.lastHash = seq, hash
+	return nil
+}
+
+// chainHash returns the hash of a record linked to the hash of the record before it
+func chainHash(previous string, payload []byte) string {
+	sum := sha256.New()
+	sum.Write([]byte(previous))
+	sum.Write(payload)
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+// ReadJournal returns every record in the journal at path after verifying the hash chain
+func ReadJournal(path string) ([]JournalRecord, error) {
+	records, _, _, err := readJournal(path)
+	return records, err
+}
+
+// readJournal reads and verifies the journal, returning the records, the last hash and the
+// length in bytes of the intact records. A final line without a newline is a write torn by a
+// crash and is ignored.
+func readJournal(path string) ([]JournalRecord, string, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	defer file.Close()
+
+	var records []JournalRecord
+	var validLength int64
+	lastHash := ""
+	reader := bufio.NewReader(file)
+	for lineNo := 1; ; lineNo++ {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			break // Either a clean end or a torn final write
+		}
+		if err != nil {
+			return nil, "", 0, err
+		}
+		validLength += int64(len(line))
+
+		line = bytes.TrimSuffix(line, []byte("\n"))
+		hash, payload, found := bytes.Cut(line, []byte(" "))
+		if !found || string(hash) != chainHash(lastHash, payload) {
+			return nil, "", 0, fmt.Errorf("%w: line %d", ErrJournalCorrupt, lineNo)
+		}
+
+		var record JournalRecord
+		if err := json.Unmarshal(payload, &record); err != nil {
+			return nil, "", 0, fmt.Errorf("%w: line %d: %v", ErrJournalCorrupt, lineNo, err)
+		}
+		if record.Seq != uint64(lineNo) {
+			return nil, "", 0, fmt.Errorf("%w: line %d has sequence %d", ErrJournalCorrupt, lineNo, record.Seq)
+		}
+
+		records = append(records, record)
+		lastHash = string(hash)
+	}
+
+	return records, lastHash, validLength, nil
+}
+
+// AttachJournal makes the lot record every state change in the journal. A new journal is
+// started with the lot's capacity and the cars already parked; a journal that already holds
+// records must have been started by this lot, e.g. before RecoverLot, or ErrJournalInUse is
+// returned.
+func (p *ParkingLot) AttachJournal(journal *Journal) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	err := journal.claim(p.id, func() []JournalRecord {
+		now := p.clock.Now()
+		records := []JournalRecord{{Type: JournalLotOpened, LotID: p.id, LotName: p.name, Time: now, Capacity: p.capacity}}
+		for _, slot := range p.slots {
+			car, ok := slot.GetCar()
+			if !ok || slot.overflow {
+				continue
+			}
+			info := p.slotInfo[slot.ID]
+			records = append(records, p.parkRecords(info, info.ParkedAt, p.ticketFor(info), p.charging[car.Plate])...)
+		}
+		return append(records, p.reservationRecords(now)...)
+	})
+	if err != nil {
+		return err
+	}
+
+	p.journal = journal
+	return nil
+}
+
+// reservationRecords returns the journal records that rebuild the lot's reservations as they
+// stand, each booking followed by its hold or how it ended. The caller must hold p.mu.
+func (p *ParkingLot) reservationRecords(now time.Time) []JournalRecord {
+	var records []JournalRecord
+	for _, reservation := range p.reservations {
+		booked := *reservation
+		booked.Status, booked.SlotID = ReservationBooked, -1
+		records = append(records, JournalRecord{Type: JournalReserved, LotID: p.id, Time: now, Reservation: &booked, SlotID: -1})
+
+		record := JournalRecord{LotID: p.id, Time: now, ReservationID: reservation.ID, SlotID: reservation.SlotID}
+		switch {
+		case reservation.Status == ReservationHonoured:
+			record.Type = JournalReservationHonoured
+		case reservation.Status == ReservationNoShow:
+			record.Type = JournalReservationNoShow
+		case reservation.Status == ReservationCancelled:
+			record.Type = JournalReservationCancelled
+		case reservation.SlotID != -1:
+			record.Type = JournalReservationHeld
+		default:
+			continue
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// ticketFor returns the ticket issued for a parked car, if any. The caller must hold p.mu.
+func (p *ParkingLot) ticketFor(info CarParkingInfo) *Ticket {
+	if issued, exists := p.tickets[info.TicketID]; exists {
+		ticket := issued.ticket
+		return &ticket
+	}
+	return nil
+}
+
+// parkRecords returns the journal records describing a car being parked, with the charging
+// session opened for it, if any
+func (p *ParkingLot) parkRecords(info CarParkingInfo, parkedAt time.Time, ticket *Ticket, session *ChargingSession) []JournalRecord {
+	car := info.Car
+	records := []JournalRecord{{
+		Type:          JournalPark,
+		LotID:         p.id,
+		Time:          parkedAt,
+		Car:           &car,
+		EnteredAs:     enteredAs(info),
+		SlotID:        info.SlotID,
+		AttendantName: info.AttendantName,
+		Ticket:        ticket,
+		PlateFlag:     info.PlateFlag,
+		SpansTwoSlots: info.SpansTwoSlots,
+		PermitFlag:    info.PermitFlag,
+		Charging:      session,
+	}}
+	if info.Row != "" || info.IsHandicap {
+		records = append(records, JournalRecord{
+			Type:       JournalRowAssigned,
+			LotID:      p.id,
+			Time:       parkedAt,
+			Car:        &car,
+			SlotID:     info.SlotID,
+			Row:        info.Row,
+			IsHandicap: info.IsHandicap,
+		})
+	}
+	return records
+}
+
+// enteredAs returns the car as typed at the gate if normalization changed it, or nil
+func enteredAs(info CarParkingInfo) *Car {
+	if info.EnteredAs == info.Car {
+		return nil
+	}
+	entered := info.EnteredAs
+	return &entered
+}
+
+// writeJournal appends records if the lot has a journal. The caller must hold p.mu.
+func (p *ParkingLot) writeJournal(records ...JournalRecord) error {
+	if p.journal == nil {
+		return nil
+	}
+	return p.journal.append(records...)
+}
+
+// ReplayJournal rebuilds a lot from the journal at path. Observers are not notified while
+// replaying; pass them as options to have them registered on the rebuilt lot.
+func ReplayJournal(path string, opts ...LotOption) (*ParkingLot, error) {
+	records, err := ReadJournal(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 || records[0].Type != JournalLotOpened {
+		return nil, fmt.Errorf("%w: journal does not start with %s", ErrJournalCorrupt, JournalLotOpened)
+	}
+
+	lot := NewParkingLot(records[0].Capacity, opts...)
+	lot.id = records[0].LotID
+	if records[0].LotName != "" {
+		lot.name = records[0].LotName
+	}
+	var own []JournalRecord
+	for _, record := range records[1:] {
+		if record.LotID == lot.id {
+			own = append(own, record)
+		}
+	}
+	if err := lot.applyJournal(own); err != nil {
+		return nil, err
+	}
+	lot.stopOrphanedCharging()
+	if lot.registry != nil {
+		lot.registry.Register(lot)
+	}
+	return lot, nil
+}
+
+// RecoverLot restores the snapshot at snapshotPath and replays the journal records written
+// after it was taken, recovering every change made between the snapshot and a crash
+func RecoverLot(snapshotPath, journalPath string, opts ...LotOption) (*ParkingLot, error) {
+	file, err := os.Open(snapshotPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var snapshot LotSnapshot
+	if err := json.NewDecoder(file).Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCorruptSnapshot, err)
+	}
+
+	records, err := ReadJournal(journalPath)
+	if err != nil {
+		return nil, err
+	}
+
+	lot, err := restoreSnapshot(snapshot, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []JournalRecord
+	for _, record := range records {
+		if record.Seq > snapshot.JournalSeq && record.LotID == lot.id {
+			pending = append(pending, record)
+		}
+	}
+	if err := lot.applyJournal(pending); err != nil {
+		return nil, err
+	}
+	lot.stopOrphanedCharging()
+	if lot.registry != nil {
+		lot.registry.Register(lot)
+	}
+	return lot, nil
+}
+
+// applyJournal replays recorded state changes onto the lot
+func (p *ParkingLot) applyJournal(records []JournalRecord) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, record := range records {
+		switch record.Type {
+		case JournalPark:
+			if record.Car == nil {
+				return fmt.Errorf("%w: cannot replay park record %d", ErrJournalCorrupt, record.Seq)
+			}
+			info := CarParkingInfo{Car: NormalizeCar(*record.Car), EnteredAs: *record.Car, SlotID: record.SlotID, AttendantName: record.AttendantName, PlateFlag: record.PlateFlag, SpansTwoSlots: record.SpansTwoSlots, PermitFlag: record.PermitFlag}
+			if record.EnteredAs != nil {
+				info.EnteredAs = *record.EnteredAs
+			}
+			if !p.slotsFree(info) {
+				return fmt.Errorf("%w: cannot replay park record %d", ErrJournalCorrupt, record.Seq)
+			}
+			if _, exists := p.slotByPlate[info.Car.Plate]; exists {
+				return fmt.Errorf("%w: park record %d for car that is already parked", ErrJournalCorrupt, record.Seq)
+			}
+			if record.Ticket != nil {
+				info.TicketID = record.Ticket.ID
+				p.tickets[record.Ticket.ID] = &issuedTicket{ticket: *record.Ticket}
+			}
+			if record.Charging != nil {
+				if record.Charging.Plate != info.Car.Plate || record.Charging.SlotID != record.SlotID {
+					return fmt.Errorf("%w: park record %d charges another car", ErrJournalCorrupt, record.Seq)
+				}
+				p.adoptCharging(*record.Charging, record.Time)
+			}
+			p.occupy(p.slots[record.SlotID], info, record.Time)
+		case JournalRowAssigned:
+			if record.Car == nil {
+				return fmt.Errorf("%w: cannot replay row record %d", ErrJournalCorrupt, record.Seq)
+			}
+			info, exists := p.parkedInfo(NormalizePlate(record.Car.Plate))
+			if !exists {
+				return fmt.Errorf("%w: row record %d for car that is not parked", ErrJournalCorrupt, record.Seq)
+			}
+			p.index.remove(info)
+			info.Row = record.Row
+			info.IsHandicap = record.IsHandicap
+			p.slotInfo[info.SlotID] = info
+			p.index.add(info)
+			p.changes++
+		case JournalUnpark:
+			if record.Car == nil {
+				return fmt.Errorf("%w: cannot replay unpark record %d", ErrJournalCorrupt, record.Seq)
+			}
+			info, exists := p.parkedInfo(NormalizePlate(record.Car.Plate))
+			if !exists {
+				return fmt.Errorf("%w: unpark record %d for car that is not parked", ErrJournalCorrupt, record.Seq)
+			}
+			p.vacate(info)
+			p.retireCharging(info.Car.Plate, record.Charging, record.Time)
+		case JournalChargingStopped:
+			if record.Charging == nil {
+				return fmt.Errorf("%w: cannot replay charging record %d", ErrJournalCorrupt, record.Seq)
+			}
+			session, exists := p.charging[record.Charging.Plate]
+			if !exists || !session.Active() || session.SlotID != record.Charging.SlotID {
+				return fmt.Errorf("%w: charging record %d for car that is not charging", ErrJournalCorrupt, record.Seq)
+			}
+			*session = *record.Charging
+		case JournalReserved:
+			if record.Reservation == nil || record.Reservation.ID == "" || p.reservationByID(record.Reservation.ID) != nil {
+				return fmt.Errorf("%w: cannot replay reserved record %d", ErrJournalCorrupt, record.Seq)
+			}
+			reservation := *record.Reservation
+			reservation.Status, reservation.SlotID = ReservationBooked, -1
+			p.reservations = append(p.reservations, &reservation)
+		case JournalReservationHeld:
+			reservation := p.reservationByID(record.ReservationID)
+			if reservation == nil || reservation.Status != ReservationBooked || reservation.SlotID != -1 || !p.canHold(record.SlotID, reservation.SlotSize) {
+				return fmt.Errorf("%w: cannot replay hold record %d", ErrJournalCorrupt, record.Seq)
+			}
+			p.hold(reservation, p.slots[record.SlotID])
+		case JournalReservationNoShow, JournalReservationCancelled:
+			reservation := p.reservationByID(record.ReservationID)
+			if reservation == nil || reservation.Status != ReservationBooked {
+				return fmt.Errorf("%w: cannot replay reservation record %d", ErrJournalCorrupt, record.Seq)
+			}
+			p.releaseHold(reservation)
+			reservation.Status = ReservationNoShow
+			if record.Type == JournalReservationCancelled {
+				reservation.Status = ReservationCancelled
+			}
+		case JournalReservationHonoured:
+			reservation := p.reservationByID(record.ReservationID)
+			if reservation == nil || reservation.Status != ReservationBooked {
+				return fmt.Errorf("%w: cannot replay honoured record %d", ErrJournalCorrupt, record.Seq)
+			}
+			p.honour(reservation, record.SlotID)
+		case JournalLotFull:
+			p.wasFull = true
+		case JournalSpaceAvailable:
+			p.wasFull = false
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Lot registry errors
+var (
+	ErrUnknownLot     = errors.New("no lot is registered with this ID")
+	ErrDuplicateLotID = errors.New("a lot with this ID is already registered")
+)
+
+// LotRegistry looks lots up by their stable ID, e.g. to resolve the lot printed on a ticket
+// or named in a police report. It is safe for concurrent use.
+type LotRegistry struct {
+	mu    sync.RWMutex
+	byID  map[string]*ParkingLot
+	order []*ParkingLot // In registration order
+}
+
+// NewLotRegistry creates an empty registry
+func NewLotRegistry() *LotRegistry {
+	return &LotRegistry{byID: make(map[string]*ParkingLot)}
+}
+
+// Register adds lots to the registry, e.g. registry.Register(garage.GetLots()...). No lot is
+// added if any of their IDs is already taken.
+func (r *LotRegistry) Register(lots ...*ParkingLot) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	seen := make(map[string]bool, len(lots))
+	for _, lot := range lots {
+		if _, taken := r.byID[lot.id]; taken || seen[lot.id] {
+			return fmt.Errorf("%w: %s", ErrDuplicateLotID, lot.id)
+		}
+		seen[lot.id] = true
+	}
+	for _, lot := range lots {
+		r.byID[lot.id] = lot
+		r.order = append(r.order, lot)
+	}
+	return nil
+}
+
+// Unregister removes the lot with the given ID
+func (r *LotRegistry) Unregister(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	lot, exists := r.byID[id]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrUnknownLot, id)
+	}
+	delete(r.byID, id)
+	r.order = removeLot(r.order, lot)
+	return nil
+}
+
+// Get returns the lot with the given ID
+func (r *LotRegistry) Get(id string) (*ParkingLot, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	lot, exists := r.byID[id]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownLot, id)
+	}
+	return lot, nil
+}
+
+// GetLots returns every registered lot in registration order, e.g. to hand to an investigation
+func (r *LotRegistry) GetLots() []*ParkingLot {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	lots := make([]*ParkingLot, len(r.order))
+	copy(lots, r.order)
+	return lots
+}
@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Vocabulary maps the spellings people type for a value to one canonical spelling,
+// e.g. "grey", "Gray" and "SILVER" all to "Silver". It is read-only once built.
+type Vocabulary struct {
+	canonical map[string]string // Lower-cased spelling to canonical value
+}
+
+// NewVocabulary builds a vocabulary from canonical values and their aliases. Each canonical
+// value is also recognised in any letter case.
+func NewVocabulary(aliases map[string][]string) *Vocabulary {
+	v := &Vocabulary{canonical: make(map[string]string)}
+	for canonical, spellings := range aliases {
+		v.canonical[vocabularyKey(canonical)] = canonical
+		for _, spelling := range spellings {
+			v.canonical[vocabularyKey(spelling)] = canonical
+		}
+	}
+	return v
+}
+
+// Canonical returns the canonical spelling of value. Values outside the vocabulary are
+// returned in title case, so "teal" and "TEAL" still agree.
+func (v *Vocabulary) Canonical(value string) string {
+	key := vocabularyKey(value)
+	if key == "" {
+		return ""
+	}
+	if canonical, known := v.canonical[key]; known {
+		return canonical
+	}
+	return titleCase(key)
+}
+
+// vocabularyKey lower-cases value and collapses runs of spaces, dashes and underscores
+func vocabularyKey(value string) string {
+	words := strings.FieldsFunc(strings.ToLower(value), func(r rune) bool {
+		return unicode.IsSpace(r) || r == '-' || r == '_'
+	})
+	return strings.Join(words, " ")
+}
+
+// titleCase upper-cases the first letter of every word
+func titleCase(value string) string {
+	words := strings.Fields(value)
+	for i, word := range words {
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		words[i] = string(runes)
+	}
+	return strings.Join(words, " ")
+}
+
+// Colors is the vocabulary cars' colors are normalized with
+var Colors = NewVocabulary(map[string][]string{
+	"White":  {"pearl white", "snow white", "off white"},
+	"Black":  {"jet black", "midnight black"},
+	"Silver": {"grey", "gray", "metallic grey", "metallic gray", "graphite"},
+	"Blue":   {"navy", "navy blue", "dark blue", "light blue"},
+	"Red":    {"maroon", "wine red", "cherry red"},
+	"Green":  {"dark green", "olive"},
+	"Yellow": {"gold", "golden"},
+	"Brown":  {"beige", "bronze"},
+	"Orange": {},
+})
+
+// Makes is the vocabulary cars' makes are normalized with
+var Makes = NewVocabulary(map[string][]string{
+	"Toyota":        {},
+	"Honda":         {},
+	"BMW":           {"bayerische motoren werke"},
+	"Mercedes-Benz": {"mercedes", "benz", "merc"},
+	"Volkswagen":    {"vw"},
+	"Chevrolet":     {"chevy"},
+	"Hyundai":       {},
+	"Ford":          {},
+	"Kia":           {},
+	"Audi":          {},
+	"Tata":          {"tata motors"},
+	"Mahindra":      {"mahindra and mahindra", "m&m"},
+	"Maruti Suzuki": {"maruti", "suzuki"},
+	"Nissan":        {"datsun"},
+})
+
+// NormalizeColor returns the canonical spelling of a color
+func NormalizeColor(color string) string {
+	return Colors.Canonical(color)
+}
+
+// NormalizeMake returns the canonical spelling of a make
+func NormalizeMake(make string) string {
+	return Makes.Canonical(make)
+}
+
+// NormalizePlate upper-cases a plate and strips spaces and dashes, so "mh-12 ab 1234"
+// and "MH12AB1234" are the same plate
+func NormalizePlate(plate string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || r == '-' {
+			return -1
+		}
+		return unicode.ToUpper(r)
+	}, plate)
+}
+
+// NormalizeCar returns the car with its plate, make and color in canonical form
+func NormalizeCar(car Car) Car {
+	car.Plate = NormalizePlate(car.Plate)
+	car.Make = NormalizeMake(car.Make)
+	car.Color = NormalizeColor(car.Color)
+	return car
+}
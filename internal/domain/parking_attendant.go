@@ -1,15 +1,47 @@
 package domain
 
+import (
+	"errors"
+	"fmt"
+)
+
 // ParkingAttendant represents an employee who parks cars
 type ParkingAttendant struct {
-	name string // Name of the attendant
+	name     string // Name of the attendant
+	strategy ParkingStrategy // Chooses the lot for Park
+}
+
+// AttendantOption configures optional parts of a parking attendant
+type AttendantOption func(*ParkingAttendant)
+
+// WithStrategy makes the attendant choose lots with the given strategy instead of DefaultStrategy
+func WithStrategy(strategy ParkingStrategy) AttendantOption {
+	return func(a *ParkingAttendant) {
+		a.strategy = strategy
+	}
 }
 
 // NewParkingAttendant creates a new parking attendant
-func NewParkingAttendant(name string) *ParkingAttendant {
-	return &ParkingAttendant{
-		name: name,
+func NewParkingAttendant(name string, opts ...AttendantOption) *ParkingAttendant {
+	a := &ParkingAttendant{
+		name:     name,
+		strategy: DefaultStrategy(),
 	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// Park parks the vehicle in the lot chosen by the attendant's strategy and returns its ticket
+func (a *ParkingAttendant) Park(lots []*ParkingLot, req ParkingRequest) (Ticket, error) {
+	if len(lots) == 0 {
+		return Ticket{}, ErrNoLotsAvailable
+	}
+	if req.Vehicle == nil {
+		return Ticket{}, fmt.Errorf("%w: no vehicle given", ErrInvalidCar)
+	}
+	return a.parkWithStrategy(lots, parkRequest{car: req.Vehicle.Record(), isHandicap: req.Handicap}, a.strategy)
 }
 
 // GetName returns the attendant's name
@@ -19,107 +51,179 @@ func (a *ParkingAttendant) GetName() string {
 
 // ParkCar parks a car in the given parking lot
 func (a *ParkingAttendant) ParkCar(lot *ParkingLot, car Car) bool {
-	return lot.Park(car)
+	return a.TryParkCar(lot, car) == nil
+}
+
+// TryParkCar parks a car in the given parking lot and reports why parking failed
+func (a *ParkingAttendant) TryParkCar(lot *ParkingLot, car Car) error {
+	_, err := a.ParkCarWithTicket(lot, car)
+	return err
+}
+
+// ParkCarWithTicket parks a car in the given parking lot and returns a ticket bearing the attendant's name
+func (a *ParkingAttendant) ParkCarWithTicket(lot *ParkingLot, car Car) (Ticket, error) {
+	if lot == nil {
+		return Ticket{}, ErrNoLotsAvailable
+	}
+	return lot.parkAndNotify(parkRequest{car: car, attendant: a.name})
 }
 
 // UnparkCar removes a car from the given parking lot
 func (a *ParkingAttendant) UnparkCar(lot *ParkingLot, car Car) bool {
-	return lot.Unpark(car)
+	return a.TryUnparkCar(lot, car) == nil
+}
+
+// TryUnparkCar removes a car from the given parking lot and reports why it failed
+func (a *ParkingAttendant) TryUnparkCar(lot *ParkingLot, car Car) error {
+	if lot == nil {
+		return ErrNoLotsAvailable
+	}
+	_, err := lot.unparkAs(car.Plate, a.name)
+	return err
+}
+
+// UnparkCarByTicket returns the car the ticket was issued for
+func (a *ParkingAttendant) UnparkCarByTicket(lot *ParkingLot, ticket Ticket) error {
+	_, err := a.CheckOutCar(lot, ticket)
+	return err
+}
+
+// CheckOutCar returns the car the ticket was issued for along with the receipt for its stay
+func (a *ParkingAttendant) CheckOutCar(lot *ParkingLot, ticket Ticket) (Receipt, error) {
+	if lot == nil {
+		return Receipt{}, ErrNoLotsAvailable
+	}
+	return lot.checkOutAs(ticket, a.name)
 }
 
 
 //use case-6
-// FindCar returns the slot number where the car is parked, or -1 if not found
+// FindCar returns the stable slot ID where the car is parked, or -1 if not found
 func (p *ParkingLot) FindCar(plateNumber string) int {
-	for i, parkedCar := range p.parkedCars {
-		if parkedCar.Plate == plateNumber {
-			return i
-		}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if info, exists := p.parkedInfo(NormalizePlate(plateNumber)); exists {
+		return info.SlotID
 	}
 	return -1
 }
 
+// LocateCar finds which of the lots the car is parked in, reporting the lot by its ID and name
+func (a *ParkingAttendant) LocateCar(lots []*ParkingLot, plateNumber string) (Location, error) {
+	for _, lot := range lots {
+		if location, found := lot.Locate(plateNumber); found {
+			return location, nil
+		}
+	}
+	return Location{}, fmt.Errorf("%w: %s", ErrCarNotFound, plateNumber)
+}
+
 //use case - 9
 // ParkCarEvenly parks a car in the lot with the fewest cars for even distribution
 func (a *ParkingAttendant) ParkCarEvenly(lots []*ParkingLot, car Car) bool {
+	return a.TryParkCarEvenly(lots, car) == nil
+}
+
+// TryParkCarEvenly is the error-returning form of ParkCarEvenly
+func (a *ParkingAttendant) TryParkCarEvenly(lots []*ParkingLot, car Car) error {
+	_, err := a.ParkCarEvenlyWithTicket(lots, car)
+	return err
+}
+
+// ParkCarEvenlyWithTicket parks like ParkCarEvenly and returns the ticket issued by the chosen lot
+func (a *ParkingAttendant) ParkCarEvenlyWithTicket(lots []*ParkingLot, car Car) (Ticket, error) {
 	if len(lots) == 0 {
-		return false
-	}
-	
-	// Find the lot with the fewest parked cars
-	var selectedLot *ParkingLot
-	minCars := -1
-	
-	for _, lot := range lots {
-		if lot.IsFull() {
-			continue // Skip full lots
-		}
-		
-		parkedCount := lot.GetParkedCarsCount()
-		if minCars == -1 || parkedCount < minCars {
-			minCars = parkedCount
-			selectedLot = lot
-		}
+		return Ticket{}, ErrNoLotsAvailable
 	}
-	
-	// If no lot is available, return false
-	if selectedLot == nil {
-		return false
-	}
-	
-	// Park the car in the selected lot
-	return selectedLot.Park(car)
+
+	return a.parkWithStrategy(lots, parkRequest{car: car}, EvenStrategy{})
 }
 
 
-// ParkHandicapCar parks a handicap car in the nearest available lot, for use case-10
+// ParkHandicapCar parks a handicap car in the nearest lot with a free accessible slot, or else
+// the nearest available lot, for use case-10. Lots checking permits reject or flag the car
+// if it has no valid permit.
 func (a *ParkingAttendant) ParkHandicapCar(lots []*ParkingLot, car Car) bool {
+	return a.TryParkHandicapCar(lots, car) == nil
+}
+
+// TryParkHandicapCar is the error-returning form of ParkHandicapCar
+func (a *ParkingAttendant) TryParkHandicapCar(lots []*ParkingLot, car Car) error {
+	_, err := a.ParkHandicapCarWithTicket(lots, car)
+	return err
+}
+
+// ParkHandicapCarWithTicket parks like ParkHandicapCar and returns the ticket issued by the chosen lot
+func (a *ParkingAttendant) ParkHandicapCarWithTicket(lots []*ParkingLot, car Car) (Ticket, error) {
     if len(lots) == 0 {
-        return false
+        return Ticket{}, ErrNoLotsAvailable
     }
-    
-    // Find the first available lot (nearest)
-    for _, lot := range lots {
-        if !lot.IsFull() {
-            return lot.Park(car)
-        }
-    }
-    
-    // No available lot found
-    return false
+
+    return a.parkWithStrategy(lots, parkRequest{car: car, isHandicap: true}, AccessibleFirstStrategy{})
 }
 
 
 //use case-11
-// ParkLargeCar parks a large car in the lot with the most available space
+// ParkLargeCar parks a large car in the lot with the most room for a car of its size
 func (a *ParkingAttendant) ParkLargeCar(lots []*ParkingLot, car Car) bool {
+	return a.TryParkLargeCar(lots, car) == nil
+}
+
+// TryParkLargeCar is the error-returning form of ParkLargeCar
+func (a *ParkingAttendant) TryParkLargeCar(lots []*ParkingLot, car Car) error {
+	_, err := a.ParkLargeCarWithTicket(lots, car)
+	return err
+}
+
+// ParkLargeCarWithTicket parks like ParkLargeCar and returns the ticket issued by the chosen lot
+func (a *ParkingAttendant) ParkLargeCarWithTicket(lots []*ParkingLot, car Car) (Ticket, error) {
     if len(lots) == 0 {
-        return false
+        return Ticket{}, ErrNoLotsAvailable
     }
-    
-    // Find the lot with the most available space
-    var selectedLot *ParkingLot
-    maxAvailableSpace := -1
-    
-    for _, lot := range lots {
-        if lot.IsFull() {
-            continue // Skip full lots
-        }
-        
-        availableSpace := lot.GetAvailableSpaces()
-        if availableSpace > maxAvailableSpace {
-            maxAvailableSpace = availableSpace
-            selectedLot = lot
-        }
-    }
-    
-    // If no lot is available, return false
-    if selectedLot == nil {
-        return false
-    }
-    
-    // Park the car in the selected lot
-    return selectedLot.Park(car)
+
+    return a.parkWithStrategy(lots, parkRequest{car: car}, MostSpaceStrategy{})
+}
+
+// parkWithStrategy parks the car in the lot the strategy chooses, see parkInChosenLot
+func (a *ParkingAttendant) parkWithStrategy(lots []*ParkingLot, req parkRequest, strategy ParkingStrategy) (Ticket, error) {
+	request := ParkingRequest{Vehicle: req.car, Handicap: req.isHandicap}
+	return a.parkInChosenLot(lots, req, func(candidates []*ParkingLot) *ParkingLot {
+		return strategy.ChooseLot(candidates, request)
+	})
 }
 
+// parkInChosenLot parks the car in the lot picked by choose. Another gate may fill the chosen
+// lot between the choice and the park, so a lot that turns out to be full is dropped and
+// choose is asked again with the remaining lots. The lot's own lock guarantees its capacity
+// is never exceeded.
+func (a *ParkingAttendant) parkInChosenLot(lots []*ParkingLot, req parkRequest, choose func([]*ParkingLot) *ParkingLot) (Ticket, error) {
+	candidates := append([]*ParkingLot(nil), lots...)
+	req.attendant = a.name
+
+	for len(candidates) > 0 {
+		selectedLot := choose(candidates)
+		if selectedLot == nil {
+			break // No lot is available
+		}
+
+		ticket, err := selectedLot.parkAndNotify(req)
+		if !errors.Is(err, ErrLotFull) {
+			return ticket, err // Parked, or rejected for a reason other than space
+		}
+
+		candidates = removeLot(candidates, selectedLot)
+	}
+
+	return Ticket{}, fmt.Errorf("%w: no lot has a free slot for a %s, cannot park %s", ErrLotFull, req.car.kind(), req.car.Plate)
+}
 
+// removeLot returns lots without the given lot
+func removeLot(lots []*ParkingLot, lot *ParkingLot) []*ParkingLot {
+	remaining := lots[:0]
+	for _, candidate := range lots {
+		if candidate != lot {
+			remaining = append(remaining, candidate)
+		}
+	}
+	return remaining
+}
@@ -1,46 +1,199 @@
 package domain
 
 import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
 //UC-16
 type CarParkingInfo struct {
-    Car        Car
+    Car        Car // Normalized, see NormalizeCar
+    EnteredAs  Car // The car exactly as entered at the gate, for display
     Row        string
     SlotID     int
     IsHandicap bool
+    AttendantName string // Attendant who parked the car, empty for self-parking
+    SpansTwoSlots bool // A large car across small slots SlotID and SlotID+1
+    TicketID   string
+    ParkedAt   time.Time
+    PlateFlag  string // Why the plate failed validation, empty if it passed
+    PermitFlag string // Why the car's handicap claim is suspect, empty if it is not
 }
 
+// ParkingLot is safe for concurrent use; observers are notified after the lot's lock is released
 type ParkingLot struct {
+	mu               sync.RWMutex // Guards every field below
+	id               string // Stable lot ID, fixed at construction
+	name             string // Display name, empty to show the ID
 	capacity         int
-	parkedCars       []Car
+	slots            []*Slot // Numbered slots with stable IDs
+	occupied         int     // Number of occupied slots, counting both slots of a large car in two
+	slotsBySize      [numSlotSizes]int // Slots of each size, fixed at construction
+	freeBySize       [numSlotSizes]int // Free slots of each size
+	freeAccessible   [numSlotSizes]int // Free accessible slots of each size, also counted in freeBySize
 	ownerObserver    Owner
 	securityObserver Security
 	wasFull bool // to track previous full state
-	parkingTimes     map[string]time.Time // Track when each car was parked for use case-8
-	carParkingInfo   map[string]CarParkingInfo // Maps plate to parking info, UC-16
+	slotByPlate      map[string]int // Maps plate to the slot holding its parking info, UC-16
+	slotInfo         []CarParkingInfo // Parking info by slot ID, the only copy kept of each parked car
+	index            *carIndex // Parked cars by color, make, size and row
+	changes          uint64    // Counts changes to slotInfo, so a queryPlan can tell it is still current
+	nextFree         int       // No slot below this ID is free
+	registry         *PlateRegistry // Shared plate registry, nil when the lot is standalone
+	tickets          map[string]*issuedTicket // Every ticket issued by the lot, by ticket ID
+	tariff           Tariff // Prices stays on exit, nil for free parking
+	clock            Clock  // Source of every timestamp the lot records
+	journal          *Journal // Records every state change, nil when not journaled
+	history          HistoryStore // Receives every completed visit, nil to keep no history
+	plateValidator      PlateValidator // Checks plates on park, nil to accept any plate
+	plateValidationMode PlateValidationMode
+	plateRejections     []PlateRejection // Cars turned away for invalid plates
+	rows                []rowRange // Row layout in slot order, empty for free-text rows
+	level               *Level     // Garage level the lot models, nil for a standalone lot
+	permits             *PermitRegistry // Handicap permits, nil to trust the handicap flag
+	permitMode          PermitEnforcementMode
+	permitRejections    []PermitRejection // Cars turned away from accessible slots
+	chargingSlots       int                 // Slots with a charger, fixed at construction
+	charger             Charger             // Meters charging sessions, nil when no charger is connected
+	charging            map[string]*ChargingSession // Sessions of parked vehicles, by plate
+	chargingLog         []ChargingSession   // Sessions of vehicles that have left
+	shifts              *ShiftSchedule      // Attendants' duty hours, nil to let any attendant work
+	valetOnly           bool                // Only attendants may park and hand back cars
+	ticketRequired      bool                // Cars are only released against their ticket
+	reservations        []*Reservation      // Every reservation, in booking order
+	reservationGrace    time.Duration       // How long a reserved slot is held past the window start
+	reservationEarly    time.Duration       // How long before the window start an arrival honours a reservation
+	held                int                 // Free slots held for reservations
+}
+
+// lotSequence numbers lots so every lot has a distinct ID
+var lotSequence atomic.Int64
+
+// LotOption configures optional parts of a parking lot at construction
+type LotOption func(*ParkingLot)
+
+// WithID gives the lot a stable ID of the caller's choosing instead of a generated "LOT-<n>".
+// The ID is printed on tickets and reported by every investigation, so it should not change
+// when the lot is restarted.
+func WithID(id string) LotOption {
+	return func(p *ParkingLot) {
+		p.id = id
+	}
+}
+
+// WithName gives the lot a display name, e.g. "Airport Terminal 2"
+func WithName(name string) LotOption {
+	return func(p *ParkingLot) {
+		p.name = name
+	}
+}
+
+// WithClock makes the lot read time from the given clock instead of the system clock
+func WithClock(clock Clock) LotOption {
+	return func(p *ParkingLot) {
+		p.clock = clock
+	}
+}
+
+// WithOwnerObserver registers the owner observer at construction or restore
+func WithOwnerObserver(owner Owner) LotOption {
+	return func(p *ParkingLot) {
+		p.ownerObserver = owner
+	}
+}
+
+// WithSecurityObserver registers the security observer at construction or restore
+func WithSecurityObserver(security Security) LotOption {
+	return func(p *ParkingLot) {
+		p.securityObserver = security
+	}
+}
+
+// WithTariff sets the tariff used to price stays
+func WithTariff(tariff Tariff) LotOption {
+	return func(p *ParkingLot) {
+		p.tariff = tariff
+	}
+}
+
+// WithPlateRegistry adds the lot to a shared plate registry
+func WithPlateRegistry(registry *PlateRegistry) LotOption {
+	return func(p *ParkingLot) {
+		p.registry = registry
+	}
+}
+
+// WithHistoryStore records every completed visit in the given store
+func WithHistoryStore(store HistoryStore) LotOption {
+	return func(p *ParkingLot) {
+		p.history = store
+	}
+}
+
+// WithTicketRequired closes the plate path: Unpark, TryUnpark, UnparkWithReceipt and the
+// attendant's UnparkCar return an error wrapping ErrTicketRequired, so a car is only released
+// to whoever holds its ticket
+func WithTicketRequired() LotOption {
+	return func(p *ParkingLot) {
+		p.ticketRequired = true
+	}
 }
 
 //constructor to create a new parking lot with required capacity
-func NewParkingLot(capacity int) *ParkingLot {
-	return &ParkingLot{
+func NewParkingLot(capacity int, opts ...LotOption) *ParkingLot {
+	lot := &ParkingLot{
+		id:         fmt.Sprintf("LOT-%d", lotSequence.Add(1)),
 		capacity:   capacity,
-		parkedCars: make([]Car, 0),
+		slots:      newSlots(capacity),
 		wasFull: false,
-		parkingTimes: make(map[string]time.Time), //added for use case -8
-		carParkingInfo: make(map[string]CarParkingInfo),
+		slotByPlate: make(map[string]int),
+		index:      newCarIndex(),
+		tickets:    make(map[string]*issuedTicket),
+		clock:      RealClock{},
+		charging:   make(map[string]*ChargingSession),
+		reservationGrace: DefaultReservationGrace,
+	}
+	lot.slotInfo = make([]CarParkingInfo, len(lot.slots))
+	for _, opt := range opts {
+		opt(lot)
+	}
+	lot.countSlotSizes()
+	return lot
+}
+
+// GetClock returns the clock the lot reads time from
+func (p *ParkingLot) GetClock() Clock {
+	return p.clock
+}
+
+// GetID returns the lot's ID, printed on every ticket it issues
+func (p *ParkingLot) GetID() string {
+	return p.id
+}
+
+// GetName returns the lot's display name, or its ID if it was not given one
+func (p *ParkingLot) GetName() string {
+	if p.name == "" {
+		return p.id
 	}
+	return p.name
 }
 
 
 //to add the owner observer
 func (p *ParkingLot) AddOwnerObserver(owner Owner) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 	p.ownerObserver = owner
 }
 
 // to add security observer
 func (p *ParkingLot) AddSecurityObserver(security Security) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 	p.securityObserver = security
 }
 
@@ -48,83 +201,540 @@ func (p *ParkingLot) AddSecurityObserver(security Security) {
 
 
 //function to park a car, if some car comes in a Parking lot for parking, it will first check the capacity of
-// parking lot and then append the car in the parked car, and notes the car plate number along with the time at which it parked
+// parking lot and then assign the car to the lowest free slot, and notes the car plate number along with the time at which it parked
 //and return true if it parked
 func (p *ParkingLot) Park(car Car) bool {
-	if len(p.parkedCars) >= p.capacity {
-		return false
+	return p.TryPark(car) == nil
+}
+
+// TryPark parks a car like Park but reports why parking failed.
+// The error wraps ErrInvalidCar (ErrInvalidPlate when the plate fails the lot's validation),
+// ErrCarAlreadyParked or ErrLotFull.
+func (p *ParkingLot) TryPark(car Car) error {
+	_, err := p.ParkWithTicket(car)
+	return err
+}
+
+// ParkWithTicket parks a car and returns the ticket the driver needs to get it back
+func (p *ParkingLot) ParkWithTicket(car Car) (Ticket, error) {
+	return p.parkAndNotify(parkRequest{car: car})
+}
+
+// parkRequest describes one car to be parked
+type parkRequest struct {
+	car        Car
+	row        string
+	isHandicap bool
+	attendant  string // Name printed on the ticket
+}
+
+// parkAndNotify parks the car under the lot lock and delivers any observer notification once the lock is released
+func (p *ParkingLot) parkAndNotify(req parkRequest) (Ticket, error) {
+	p.mu.Lock()
+	held, err := p.refreshReservations(p.clock.Now())
+	if err != nil {
+		p.mu.Unlock()
+		return Ticket{}, err
+	}
+	ticket, event, err := p.park(req)
+	p.mu.Unlock()
+
+	p.notify(held)
+	p.notify(event)
+	var duplicate *DuplicatePlateError
+	if errors.As(err, &duplicate) {
+		p.notifyDuplicatePlate(duplicate.Conflict)
+	}
+	return ticket, err
+}
+
+// park assigns the car to the free slot that best fits it and issues a ticket for it.
+// The caller must hold p.mu for writing.
+func (p *ParkingLot) park(req parkRequest) (Ticket, lotEvent, error) {
+	car := NormalizeCar(req.car)
+	if car.Plate == "" {
+		return Ticket{}, noEvent, fmt.Errorf("%w: plate is empty", ErrInvalidCar)
+	}
+	if err := p.checkValet(req.attendant); err != nil {
+		return Ticket{}, noEvent, err
+	}
+	if err := p.checkOnDuty(req.attendant, p.clock.Now()); err != nil {
+		return Ticket{}, noEvent, err
+	}
+	plateFlag, err := p.validatePlate(car, req.car)
+	if err != nil {
+		return Ticket{}, noEvent, err
 	}
 
-	p.parkedCars = append(p.parkedCars, car)
+	// A plate can only hold one slot at a time
+	if existing, exists := p.parkedInfo(car.Plate); exists {
+		conflict := PlateConflict{
+			Plate:        car.Plate,
+			ParkedCar:    existing.Car,
+			ParkedLot:    p,
+			ParkedSlotID: existing.SlotID,
+			RejectedCar:  req.car,
+			AttemptedLot: p,
+			DetectedAt:   p.clock.Now(),
+		}
+		if p.registry != nil {
+			p.registry.recordConflict(conflict)
+		}
+		return Ticket{}, noEvent, &DuplicatePlateError{Conflict: conflict}
+	}
+
+	handicap, permitFlag, err := p.checkPermit(car, req)
+	if err != nil {
+		return Ticket{}, noEvent, err
+	}
+
+	// A car with a reservation takes the slot held for it
+	slot, spansTwo := p.reservedSlotFor(car), false
+	if slot == nil {
+		slot, spansTwo, err = p.freeSlotFor(req.row, car, handicap)
+		if err != nil {
+			return Ticket{}, noEvent, err
+		}
+	}
+
+	// The plate must not be parked in any other lot sharing the registry,
+	// checked before capacity so a cloned plate is flagged even at a full lot
+	if p.registry != nil {
+		slotID := -1
+		if slot != nil {
+			slotID = slot.ID
+		}
+		if err := p.registry.claim(car, req.car, p, slotID, p.clock.Now()); err != nil {
+			return Ticket{}, noEvent, err
+		}
+	}
+
+	if slot == nil {
+		if p.registry != nil {
+			p.registry.release(car.Plate, p)
+		}
+		switch {
+		case len(p.rows) > 0 && req.row != "":
+			return Ticket{}, noEvent, fmt.Errorf("%w: row %s has no free slot for a %s, cannot park %s", ErrLotFull, req.row, car.kind(), car.Plate)
+		case p.occupied < p.capacity:
+			return Ticket{}, noEvent, fmt.Errorf("%w: no free slot fits a %s, cannot park %s", ErrLotFull, car.kind(), car.Plate)
+		}
+		return Ticket{}, noEvent, fmt.Errorf("%w: cannot park %s", ErrLotFull, car.Plate)
+	}
+	row := req.row
+	if len(p.rows) > 0 {
+		row = slot.Row
+	}
 
 	// Record parking time for use case -8
-    p.parkingTimes[car.Plate] = time.Now()
-
-	// Notify owner if lot is now full
-    if len(p.parkedCars) == p.capacity {
-        if p.ownerObserver != nil {
-            p.ownerObserver.OnLotFull("Lot is full")
-        }
-        if p.securityObserver != nil {
-            p.securityObserver.OnLotFull("Lot is full")
-        }
+	now := p.clock.Now()
+	ticket := Ticket{
+		ID:            newTicketID(),
+		LotID:         p.id,
+		SlotID:        slot.ID,
+		Plate:         car.Plate,
+		EntryTime:     now,
+		AttendantName: req.attendant,
+	}
+	info := CarParkingInfo{
+		Car:           car,
+		EnteredAs:     req.car,
+		Row:           row,
+		SlotID:        slot.ID,
+		IsHandicap:    handicap,
+		AttendantName: req.attendant,
+		TicketID:      ticket.ID,
+		PlateFlag:     plateFlag,
+		SpansTwoSlots: spansTwo,
+		PermitFlag:    permitFlag,
+	}
+
+	// Owner and security are told once the lot becomes full
+	event := noEvent
+	session := p.startCharging(slot, info, now)
+	records := p.parkRecords(info, now, &ticket, session)
+	heldAfter := p.held
+	if slot.reservation != "" {
+		heldAfter--
+	}
+	reservation := p.reservationToHonour(car.Plate, now)
+	if reservation != nil {
+		records = append(records, JournalRecord{Type: JournalReservationHonoured, LotID: p.id, Time: now, ReservationID: reservation.ID, SlotID: slot.ID})
+		if reservation.SlotID != -1 && reservation.SlotID != slot.ID {
+			heldAfter--
+		}
+	}
+	if !p.wasFull && p.occupied+slotsHeld(info)+heldAfter == p.capacity {
+		event = lotFullEvent
+		records = append(records, JournalRecord{Type: JournalLotFull, LotID: p.id, Time: now})
+	}
+
+	// The journal is written ahead of the change so a failed write leaves the lot untouched
+	if err := p.writeJournal(records...); err != nil {
+		p.cancelCharging(session, now)
+		if p.registry != nil {
+			p.registry.release(car.Plate, p)
+		}
+		return Ticket{}, noEvent, err
+	}
+
+	p.tickets[ticket.ID] = &issuedTicket{ticket: ticket}
+	p.occupy(slot, info, now)
+	if reservation != nil {
+		p.honour(reservation, slot.ID)
+	}
+	if session != nil {
+		p.charging[car.Plate] = session
+	}
+	if event == lotFullEvent {
 		p.wasFull = true
-    }
+	}
+
+	return ticket, event, nil
+}
+
+// occupy places a car in a slot with its parking details. The caller must hold p.mu for writing.
+func (p *ParkingLot) occupy(slot *Slot, info CarParkingInfo, parkedAt time.Time) {
+	info.ParkedAt = parkedAt
+	if slot.reservation != "" {
+		p.unhold(slot)
+	}
+	slot.assign(info.Car)
+	p.freeBySize[slot.Size]--
+	if slot.Accessible {
+		p.freeAccessible[slot.Size]--
+	}
+	if info.SpansTwoSlots {
+		back := p.slots[slot.ID+1]
+		back.assignOverflow(info.Car)
+		p.freeBySize[back.Size]--
+	}
+	p.occupied += slotsHeld(info)
+	p.slotByPlate[info.Car.Plate] = slot.ID
+	p.slotInfo[slot.ID] = info
+	p.index.add(info)
+	p.changes++
+}
+
+// parkedInfo returns the parking details of the car with the normalized plate, if it is
+// parked. The caller must hold p.mu.
+func (p *ParkingLot) parkedInfo(plateNumber string) (CarParkingInfo, bool) {
+	slotID, parked := p.slotByPlate[plateNumber]
+	if !parked {
+		return CarParkingInfo{}, false
+	}
+	return p.slotInfo[slotID], true
+}
+
+// vacate frees the slot held by a parked car and voids its ticket. The caller must hold p.mu for writing.
+func (p *ParkingLot) vacate(info CarParkingInfo) {
+	plateNumber := info.Car.Plate
+	for id := info.SlotID; id < info.SlotID+slotsHeld(info); id++ {
+		p.slots[id].release()
+		p.freeBySize[p.slots[id].Size]++
+		if p.slots[id].Accessible {
+			p.freeAccessible[p.slots[id].Size]++
+		}
+	}
+	p.occupied -= slotsHeld(info)
+	if issued, exists := p.tickets[info.TicketID]; exists {
+		issued.used = true
+	}
 
-	return true
+	delete(p.slotByPlate, plateNumber)
+	p.slotInfo[info.SlotID] = CarParkingInfo{}
+	p.index.remove(info)
+	p.changes++
+	if info.SlotID < p.nextFree {
+		p.nextFree = info.SlotID
+	}
+	if p.registry != nil {
+		p.registry.release(plateNumber, p)
+	}
+}
+
+// slotsHeld returns the number of slots a parked car takes up
+func slotsHeld(info CarParkingInfo) int {
+	if info.SpansTwoSlots {
+		return 2
+	}
+	return 1
+}
+
+// firstFreeSlot returns the free slot with the lowest ID, or nil if the lot is full.
+// The search starts at p.nextFree so filling a large lot does not rescan taken slots.
+func (p *ParkingLot) firstFreeSlot() *Slot {
+	for ; p.nextFree < len(p.slots); p.nextFree++ {
+		if slot := p.slots[p.nextFree]; !slot.IsOccupied() {
+			return slot
+		}
+	}
+	return nil
 }
 
 //to unpark the car from the lot
 func (p *ParkingLot) Unpark(car Car) bool {
-	for i, parkedCar := range p.parkedCars {
-		if parkedCar.Plate == car.Plate {
-			p.parkedCars = append(p.parkedCars[:i], p.parkedCars[i+1:]...)
-            
-			// Remove parking time record for use case-8
-            delete(p.parkingTimes, car.Plate)
+	return p.TryUnpark(car) == nil
+}
+
+// TryUnpark removes a car like Unpark but returns an error wrapping ErrCarNotFound
+// when the plate is not parked in this lot. The car's ticket can no longer be used afterwards.
+func (p *ParkingLot) TryUnpark(car Car) error {
+	_, err := p.UnparkWithReceipt(car)
+	return err
+}
+
+// UnparkWithReceipt removes a car by plate and returns the receipt for its stay
+func (p *ParkingLot) UnparkWithReceipt(car Car) (Receipt, error) {
+	return p.unparkAs(car.Plate, "")
+}
+
+// unparkAs removes a car by plate on behalf of the named attendant, empty when the driver
+// collects the car themselves
+func (p *ParkingLot) unparkAs(plateNumber, attendant string) (Receipt, error) {
+	p.mu.Lock()
+	if err := p.checkPlateRelease(attendant); err != nil {
+		p.mu.Unlock()
+		return Receipt{}, err
+	}
+	held, err := p.refreshReservations(p.clock.Now())
+	if err != nil {
+		p.mu.Unlock()
+		return Receipt{}, err
+	}
+	receipt, event, err := p.unpark(NormalizePlate(plateNumber), attendant)
+	p.mu.Unlock()
+
+	p.notify(held)
+	p.notify(event)
+	return receipt, err
+}
+
+// checkPlateRelease returns an error if the lot will not release a car by plate alone to the
+// named attendant, or to the driver when empty. The caller must hold p.mu.
+func (p *ParkingLot) checkPlateRelease(attendant string) error {
+	if err := p.checkValet(attendant); err != nil {
+		return err
+	}
+	if p.ticketRequired {
+		return fmt.Errorf("%w: %s", ErrTicketRequired, p.id)
+	}
+	return nil
+}
+
+// UnparkByTicket releases the car the ticket was issued for. A ticket is accepted once;
+// tickets this lot never issued, or whose details were altered, are rejected.
+func (p *ParkingLot) UnparkByTicket(ticket Ticket) error {
+	_, err := p.CheckOut(ticket)
+	return err
+}
 
-			//Notify owner if lot has space available
-			if p.wasFull && len(p.parkedCars) == p.capacity-1 {
-				if p.ownerObserver != nil {
-					p.ownerObserver.OnSpaceAvailable("Space is Available")
-				}
-				p.wasFull = false
-			}
+// CheckOut releases the car the ticket was issued for, like UnparkByTicket, and returns the receipt for its stay
+func (p *ParkingLot) CheckOut(ticket Ticket) (Receipt, error) {
+	return p.checkOutAs(ticket, "")
+}
+
+// checkOutAs releases the car the ticket was issued for on behalf of the named attendant
+func (p *ParkingLot) checkOutAs(ticket Ticket, attendant string) (Receipt, error) {
+	p.mu.Lock()
+	held, err := p.refreshReservations(p.clock.Now())
+	if err != nil {
+		p.mu.Unlock()
+		return Receipt{}, err
+	}
+	receipt, event, err := p.unparkByTicket(ticket, attendant)
+	p.mu.Unlock()
+
+	p.notify(held)
+	p.notify(event)
+	return receipt, err
+}
+
+// unparkByTicket validates the ticket and frees its slot. The caller must hold p.mu for writing.
+func (p *ParkingLot) unparkByTicket(ticket Ticket, attendant string) (Receipt, lotEvent, error) {
+	issued, exists := p.tickets[ticket.ID]
+	if !exists || !ticket.matches(issued.ticket) {
+		return Receipt{}, noEvent, fmt.Errorf("%w: %s", ErrInvalidTicket, ticket.ID)
+	}
+	if issued.used {
+		return Receipt{}, noEvent, fmt.Errorf("%w: %s", ErrTicketAlreadyUsed, ticket.ID)
+	}
+	return p.unpark(ticket.Plate, attendant)
+}
+
+// unpark frees the slot held by the plate and prices the stay. The attendant handing the car
+// back is recorded with the visit. The caller must hold p.mu for writing.
+func (p *ParkingLot) unpark(plateNumber, attendant string) (Receipt, lotEvent, error) {
+	info, exists := p.parkedInfo(plateNumber)
+	if !exists {
+		return Receipt{}, noEvent, fmt.Errorf("%w: %s", ErrCarNotFound, plateNumber)
+	}
+	if err := p.checkValet(attendant); err != nil {
+		return Receipt{}, noEvent, err
+	}
+	now := p.clock.Now()
+	if err := p.checkOnDuty(attendant, now); err != nil {
+		return Receipt{}, noEvent, err
+	}
+	receipt := p.receiptFor(info, now)
+
+	//Notify owner if lot has space available
+	event := noEvent
+	car := info.Car
+	records := []JournalRecord{{Type: JournalUnpark, LotID: p.id, Time: now, Car: &car, SlotID: info.SlotID, AttendantName: info.AttendantName, Charging: p.endedSession(car.Plate, now)}}
+	if p.wasFull && p.occupied+p.held == p.capacity {
+		event = spaceAvailableEvent
+		records = append(records, JournalRecord{Type: JournalSpaceAvailable, LotID: p.id, Time: now})
+	}
+
+	if err := p.writeJournal(records...); err != nil {
+		return Receipt{}, noEvent, err
+	}
+
+	p.vacate(info)
+	p.endCharging(info.Car.Plate, now)
+	if p.history != nil {
+		p.history.Record(p.visitOf(info, now, attendant))
+	}
+	if event == spaceAvailableEvent {
+		p.wasFull = false
+	}
+
+	return receipt, event, nil
+}
+
+// visitOf describes the stay of a parked car leaving at exitTime, zero if it has not left.
+// The caller must hold p.mu.
+func (p *ParkingLot) visitOf(info CarParkingInfo, exitTime time.Time, exitAttendant string) Visit {
+	return Visit{
+		Plate:             info.Car.Plate,
+		Make:              info.Car.Make,
+		Color:             info.Car.Color,
+		Size:              info.Car.Size,
+		Type:              info.Car.Type,
+		LotID:             p.id,
+		SlotID:            info.SlotID,
+		Row:               info.Row,
+		IsHandicap:        info.IsHandicap,
+		EntryTime:         info.ParkedAt,
+		ExitTime:          exitTime,
+		AttendantName:     info.AttendantName,
+		ExitAttendantName: exitAttendant,
+	}
+}
 
-			return true
+// receiptFor prices the stay of a parked car leaving at exitTime. The caller must hold p.mu.
+func (p *ParkingLot) receiptFor(info CarParkingInfo, exitTime time.Time) Receipt {
+	receipt := Receipt{
+		TicketID:  info.TicketID,
+		LotID:     p.id,
+		SlotID:    info.SlotID,
+		Plate:     info.Car.Plate,
+		Size:      info.Car.Size,
+		Type:      info.Car.Type,
+		EntryTime: info.ParkedAt,
+		ExitTime:  exitTime,
+		EnergyKWh: p.chargedEnergy(info.Car.Plate, exitTime),
+	}
+	receipt.Duration = receipt.ExitTime.Sub(receipt.EntryTime)
+
+	if p.tariff != nil {
+		stay := Stay{
+			Plate:     receipt.Plate,
+			Size:      receipt.Size,
+			Type:      receipt.Type,
+			EnergyKWh: receipt.EnergyKWh,
+			EntryTime: receipt.EntryTime,
+			ExitTime:  receipt.ExitTime,
+		}
+		for _, item := range p.tariff.Charge(stay) {
+			receipt.addItem(item.Description, item.Amount)
 		}
 	}
-	return false
+	return receipt
+}
+
+// SetHistoryStore sets the store that receives every completed visit
+func (p *ParkingLot) SetHistoryStore(store HistoryStore) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.history = store
+}
+
+// SetTariff sets the tariff used to price stays when cars leave; without one every receipt is free
+func (p *ParkingLot) SetTariff(tariff Tariff) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tariff = tariff
+}
+
+// GetPlateRegistry returns the registry the lot belongs to, or nil for a standalone lot
+func (p *ParkingLot) GetPlateRegistry() *PlateRegistry {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.registry
+}
+
+// GetSlot returns a copy of the slot with the given ID
+func (p *ParkingLot) GetSlot(slotID int) (Slot, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if slotID < 0 || slotID >= len(p.slots) {
+		return Slot{}, false
+	}
+	return *p.slots[slotID], true
+}
+
+// GetSlots returns a copy of every slot in the lot ordered by slot ID
+func (p *ParkingLot) GetSlots() []Slot {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	slots := make([]Slot, len(p.slots))
+	for i, slot := range p.slots {
+		slots[i] = *slot
+	}
+	return slots
 }
 
 //to get the number of currently parked cars
 func (p *ParkingLot) GetParkedCarsCount() int {
-	return len(p.parkedCars)
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return len(p.slotByPlate)
 }
 
 //to check whether the parking lot is full or not
 func (p *ParkingLot) IsFull() bool {
-	return len(p.parkedCars) == p.capacity
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.occupied+p.heldAt(p.clock.Now()) == p.capacity
 }
 
 // changed function name for use case-11
-// to get the space available in the lot
+// to get the number of free slots in the lot, see GetAvailableSpacesFor for room by car size
+// Slots held for reservations as of now are not available.
 func(p *ParkingLot) GetAvailableSpaces() int {
-	return p.capacity - len(p.parkedCars)
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.capacity - p.occupied - p.heldAt(p.clock.Now())
 }
 
 // GetParkingTime returns when a car was parked, use case -8
 func (p *ParkingLot) GetParkingTime(plateNumber string) time.Time {
-    if parkTime, exists := p.parkingTimes[plateNumber]; exists {
-        return parkTime
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+    if info, exists := p.parkedInfo(NormalizePlate(plateNumber)); exists {
+        return info.ParkedAt
     }
     return time.Time{} // Zero time if not found
 }
 
 // GetParkingDuration returns how long a car has been parked, use-case 8
 func (p *ParkingLot) GetParkingDuration(plateNumber string) time.Duration {
-    if parkTime, exists := p.parkingTimes[plateNumber]; exists {
-        return time.Since(parkTime)
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+    if info, exists := p.parkedInfo(NormalizePlate(plateNumber)); exists {
+        return p.clock.Now().Sub(info.ParkedAt)
     }
     return 0 // Zero duration if not found
 }
@@ -133,118 +743,116 @@ func (p *ParkingLot) GetParkingDuration(plateNumber string) time.Duration {
 //use case-12
 // FindCarsByColor returns all cars of a specific color
 func (p *ParkingLot) FindCarsByColor(color string) []Car {
-    var matchingCars []Car
-    
-    for _, parkedCar := range p.parkedCars {
-        if parkedCar.Color == color {
-            matchingCars = append(matchingCars, parkedCar)
-        }
-    }
-    
-    return matchingCars
+    return carsOf(p.Query(NewQuery().Where(ColorIs(color))))
 }
 
 // FindCarsByMakeAndColor returns all cars of a specific make and color
 func (p *ParkingLot) FindCarsByMakeAndColor(make string, color string) []Car {
-    var matchingCars []Car
-    
-    for _, parkedCar := range p.parkedCars {
-        if parkedCar.Make == make && parkedCar.Color == color {
-            matchingCars = append(matchingCars, parkedCar)
-        }
-    }
-    
-    return matchingCars
+    return carsOf(p.Query(NewQuery().Where(MakeIs(make)).Where(ColorIs(color))))
 }
 
 //use case - 14
 // FindCarsByMake returns all cars of a specific make
 func (p *ParkingLot) FindCarsByMake(make string) []Car {
-    var matchingCars []Car
-    
-    for _, parkedCar := range p.parkedCars {
-        if parkedCar.Make == make {
-            matchingCars = append(matchingCars, parkedCar)
-        }
-    }
-    
-    return matchingCars
+    return carsOf(p.Query(NewQuery().Where(MakeIs(make))))
 }
 
 //use case-15
 // FindCarsParkedInLastMinutes returns all cars parked within the specified number of minutes
 func (p *ParkingLot) FindCarsParkedInLastMinutes(minutes int) []Car {
-    var recentCars []Car
-    cutoffTime := time.Now().Add(-time.Duration(minutes) * time.Minute)
-    
-    for _, parkedCar := range p.parkedCars {
-        if parkTime, exists := p.parkingTimes[parkedCar.Plate]; exists {
-            if parkTime.After(cutoffTime) {
-                recentCars = append(recentCars, parkedCar)
-            }
-        }
-    }
-    
-    return recentCars
-}
-
-// SetParkingTime sets the parking time for a car (used for testing)
-func (p *ParkingLot) SetParkingTime(plateNumber string, parkTime time.Time) {
-    p.parkingTimes[plateNumber] = parkTime
+    return carsOf(p.Query(NewQuery().Where(ParkedWithin(time.Duration(minutes) * time.Minute))))
 }
 
 //UC-16
 // ParkInRow parks a car in a specific row with handicap designation
 func (p *ParkingLot) ParkInRow(car Car, row string, isHandicap bool) bool {
-    if len(p.parkedCars) >= p.capacity {
-        return false
-    }
-    
-    // Park the car normally
-    if !p.Park(car) {
-        return false
-    }
-    
-    // Store additional parking information
-    slotID := len(p.parkedCars) - 1 // Last added car's slot
-    parkingInfo := CarParkingInfo{
-        Car:        car,
-        Row:        row,
-        SlotID:     slotID,
-        IsHandicap: isHandicap,
-    }
-    p.carParkingInfo[car.Plate] = parkingInfo
-    
-    return true
+    return p.TryParkInRow(car, row, isHandicap) == nil
+}
+
+// TryParkInRow parks a car in a row like ParkInRow but reports why parking failed
+func (p *ParkingLot) TryParkInRow(car Car, row string, isHandicap bool) error {
+    // Slot assignment and row details are recorded together under the lot lock
+    _, err := p.parkAndNotify(parkRequest{car: car, row: row, isHandicap: isHandicap})
+    return err
 }
 
 // FindSmallHandicapCarsInRows finds small handicap cars in specified rows
 func (p *ParkingLot) FindSmallHandicapCarsInRows(targetRows []string) []CarParkingInfo {
-    var matchingCars []CarParkingInfo
-    
-    // Create a map for quick row lookup
-    rowMap := make(map[string]bool)
-    for _, row := range targetRows {
-        rowMap[row] = true
-    }
-    
-    for _, parkingInfo := range p.carParkingInfo {
-        // Check if car meets all criteria
-        if parkingInfo.Car.Size == Small && 
-           parkingInfo.IsHandicap && 
-           rowMap[parkingInfo.Row] {
-            matchingCars = append(matchingCars, parkingInfo)
-        }
-    }
-    
-    return matchingCars
+    return p.Query(NewQuery().Where(SizeIs(Small)).Where(HandicapIs(true)).Where(RowIn(targetRows...)))
 }
 
 //UC-17
-// GetAllParkedCars returns all currently parked cars
+// GetAllParkedCars returns all currently parked cars ordered by slot ID
 func (p *ParkingLot) GetAllParkedCars() []Car {
-    // Return a copy of the parked cars slice to prevent external modification
-    allCars := make([]Car, len(p.parkedCars))
-    copy(allCars, p.parkedCars)
-    return allCars
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+    // Build a fresh slice so callers cannot modify the lot's slots
+    return p.parkedCarsInSlotOrder()
+}
+
+// parkedCarsInSlotOrder returns the cars in occupied slots ordered by slot ID.
+// The caller must hold p.mu.
+func (p *ParkingLot) parkedCarsInSlotOrder() []Car {
+	cars := make([]Car, 0, len(p.slotByPlate))
+	for _, slot := range p.slots {
+		if car, ok := slot.GetCar(); ok && !slot.overflow {
+			cars = append(cars, car)
+		}
+	}
+	return cars
+}
+
+// lotEvent is an observer notification raised while the lot lock is held
+type lotEvent int
+
+const (
+	noEvent lotEvent = iota
+	lotFullEvent
+	spaceAvailableEvent
+	reservedFullEvent // The lot became full because slots were held for reservations
+)
+
+// notify delivers an observer notification; it must be called without holding p.mu
+// so observers are free to query the lot
+func (p *ParkingLot) notify(event lotEvent) {
+	if event == noEvent {
+		return
+	}
+
+	p.mu.RLock()
+	owner, security := p.ownerObserver, p.securityObserver
+	p.mu.RUnlock()
+
+	switch event {
+	case lotFullEvent:
+		if owner != nil {
+			owner.OnLotFull("Lot is full")
+		}
+		if security != nil {
+			security.OnLotFull("Lot is full")
+		}
+	case reservedFullEvent:
+		if owner != nil {
+			owner.OnLotFull("Lot is full with reserved slots held")
+		}
+		if security != nil {
+			security.OnLotFull("Lot is full with reserved slots held")
+		}
+	case spaceAvailableEvent:
+		if owner != nil {
+			owner.OnSpaceAvailable("Space is Available")
+		}
+	}
+}
+
+// notifyDuplicatePlate raises a security event for a rejected duplicate plate; it must be
+// called without holding p.mu
+func (p *ParkingLot) notifyDuplicatePlate(conflict PlateConflict) {
+	p.mu.RLock()
+	security := p.securityObserver
+	p.mu.RUnlock()
+
+	if observer, ok := security.(DuplicatePlateObserver); ok {
+		observer.OnDuplicatePlate(conflict)
+	}
 }
@@ -0,0 +1,158 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Permit errors returned when a car claims an accessible slot
+var (
+	ErrNoPermit      = errors.New("no valid handicap permit")
+	ErrPermitExpired = fmt.Errorf("%w: permit has expired", ErrNoPermit)
+)
+
+// Permit is a handicap parking permit issued for one plate
+type Permit struct {
+	Plate     string
+	Holder    string
+	ExpiresAt time.Time
+}
+
+// PermitRegistry holds the handicap permits lots check accessible parking against. It can be
+// shared by several lots and is safe for concurrent use.
+type PermitRegistry struct {
+	mu      sync.RWMutex
+	permits map[string]Permit // By normalized plate
+}
+
+// NewPermitRegistry creates a registry holding the given permits
+func NewPermitRegistry(permits ...Permit) *PermitRegistry {
+	r := &PermitRegistry{permits: make(map[string]Permit)}
+	for _, permit := range permits {
+		r.Issue(permit)
+	}
+	return r
+}
+
+// Issue records a permit, replacing any earlier permit for the same plate
+func (r *PermitRegistry) Issue(permit Permit) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	permit.Plate = NormalizePlate(permit.Plate)
+	r.permits[permit.Plate] = permit
+}
+
+// Revoke removes the permit for a plate
+func (r *PermitRegistry) Revoke(plate string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.permits, NormalizePlate(plate))
+}
+
+// GetPermit returns the permit issued for a plate, valid or not
+func (r *PermitRegistry) GetPermit(plate string) (Permit, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	permit, exists := r.permits[NormalizePlate(plate)]
+	return permit, exists
+}
+
+// Verify returns nil if the plate holds a permit valid at the given time, and otherwise an
+// error wrapping ErrNoPermit or ErrPermitExpired
+func (r *PermitRegistry) Verify(plate string, at time.Time) error {
+	permit, exists := r.GetPermit(plate)
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrNoPermit, NormalizePlate(plate))
+	}
+	if !at.Before(permit.ExpiresAt) {
+		return fmt.Errorf("%w: %s on %s", ErrPermitExpired, permit.Plate, permit.ExpiresAt.Format(time.DateOnly))
+	}
+	return nil
+}
+
+// WithAccessibleSlots reserves slots for cars with handicap permits, typically the slots
+// nearest the entrance. Other cars are never placed in them.
+func WithAccessibleSlots(slotIDs ...int) LotOption {
+	return func(p *ParkingLot) {
+		for _, id := range slotIDs {
+			if id >= 0 && id < len(p.slots) {
+				p.slots[id].Accessible = true
+			}
+		}
+	}
+}
+
+// PermitEnforcementMode selects what a lot does with a car that claims an accessible slot
+// without a valid permit
+type PermitEnforcementMode int
+
+const (
+	RejectUnpermitted PermitEnforcementMode = iota // Refuse to park the car
+	FlagUnpermitted                                // Park the car but report it to the police
+)
+
+// WithPermits checks handicap permits in the registry. Permitted cars are placed in accessible
+// slots whether or not they ask for one, and cars asking without a valid permit are handled
+// according to mode. Lots without a registry trust the handicap flag they are given.
+func WithPermits(registry *PermitRegistry, mode PermitEnforcementMode) LotOption {
+	return func(p *ParkingLot) {
+		p.permits = registry
+		p.permitMode = mode
+	}
+}
+
+// PermitRejection records a car turned away from accessible parking
+type PermitRejection struct {
+	Car        Car // As entered at the gate
+	Reason     string
+	RejectedAt time.Time
+}
+
+// GetPermitRejections returns the cars turned away for claiming accessible parking without a permit
+func (p *ParkingLot) GetPermitRejections() []PermitRejection {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	rejections := make([]PermitRejection, len(p.permitRejections))
+	copy(rejections, p.permitRejections)
+	return rejections
+}
+
+// GetAvailableAccessibleSpaces returns the number of free accessible slots
+func (p *ParkingLot) GetAvailableAccessibleSpaces() int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	free := 0
+	for _, count := range p.freeAccessible {
+		free += count
+	}
+	return free
+}
+
+// checkPermit decides whether a car is parked as a handicap car. It returns the reason to flag
+// the car for, or an error if the car must be turned away. The caller must hold p.mu for writing.
+func (p *ParkingLot) checkPermit(car Car, req parkRequest) (handicap bool, flag string, err error) {
+	if p.permits == nil {
+		return req.isHandicap, "", nil
+	}
+	err = p.permits.Verify(car.Plate, p.clock.Now())
+	switch {
+	case err == nil:
+		return true, "", nil
+	case !req.isHandicap:
+		return false, "", nil
+	case p.permitMode == FlagUnpermitted:
+		return true, permitReason(err), nil
+	}
+	p.permitRejections = append(p.permitRejections, PermitRejection{Car: req.car, Reason: permitReason(err), RejectedAt: p.clock.Now()})
+	return false, "", err
+}
+
+// permitReason describes a failed permit check for police reports
+func permitReason(err error) string {
+	if errors.Is(err, ErrPermitExpired) {
+		return "handicap permit has expired"
+	}
+	return "no handicap permit"
+}
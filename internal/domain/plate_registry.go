@@ -0,0 +1,144 @@
+package domain
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// PlateConflict records an attempt to park a plate that is already parked somewhere in the registry
+type PlateConflict struct {
+	Plate        string
+	ParkedCar    Car         // Car already holding the plate
+	ParkedLot    *ParkingLot // Lot where the plate is parked
+	ParkedSlotID int
+	RejectedCar  Car         // Car that was turned away
+	AttemptedLot *ParkingLot // Lot the rejected car tried to enter
+	DetectedAt   time.Time
+}
+
+// DuplicatePlateError is returned when a plate is already parked in the same lot or in another
+// lot of the same registry. It wraps ErrCarAlreadyParked.
+type DuplicatePlateError struct {
+	Conflict PlateConflict
+}
+
+func (e *DuplicatePlateError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrCarAlreadyParked, e.Conflict.Plate)
+}
+
+func (e *DuplicatePlateError) Unwrap() error {
+	return ErrCarAlreadyParked
+}
+
+// plateEntry is where a registered plate is currently parked
+type plateEntry struct {
+	car    Car
+	lot    *ParkingLot
+	slotID int
+}
+
+// PlateRegistry tracks which plates are parked across a set of lots, so the same plate
+// cannot be parked twice, and keeps every rejected attempt for cloned-plate investigations
+type PlateRegistry struct {
+	mu        sync.Mutex
+	plates    map[string]plateEntry
+	conflicts []PlateConflict
+}
+
+// NewPlateRegistry creates a registry spanning the given lots
+func NewPlateRegistry(lots ...*ParkingLot) *PlateRegistry {
+	r := &PlateRegistry{
+		plates: make(map[string]plateEntry),
+	}
+	for _, lot := range lots {
+		r.Register(lot)
+	}
+	return r
+}
+
+// Register adds a lot to the registry, including any cars already parked in it
+func (r *PlateRegistry) Register(lot *ParkingLot) {
+	lot.mu.Lock()
+	defer lot.mu.Unlock()
+
+	lot.registry = r
+	for plate, slotID := range lot.slotByPlate {
+		info := lot.slotInfo[slotID]
+		r.mu.Lock()
+		r.plates[plate] = plateEntry{car: info.Car, lot: lot, slotID: info.SlotID}
+		r.mu.Unlock()
+	}
+}
+
+// IsParked returns true if the plate is parked in any registered lot
+func (r *PlateRegistry) IsParked(plateNumber string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, exists := r.plates[NormalizePlate(plateNumber)]
+	return exists
+}
+
+// GetConflicts returns every duplicate-plate attempt seen so far
+func (r *PlateRegistry) GetConflicts() []PlateConflict {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	conflicts := make([]PlateConflict, len(r.conflicts))
+	copy(conflicts, r.conflicts)
+	return conflicts
+}
+
+// GetConflictsForPlate returns the duplicate attempts recorded against one plate
+func (r *PlateRegistry) GetConflictsForPlate(plateNumber string) []PlateConflict {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	plateNumber = NormalizePlate(plateNumber)
+	var conflicts []PlateConflict
+	for _, conflict := range r.conflicts {
+		if conflict.Plate == plateNumber {
+			conflicts = append(conflicts, conflict)
+		}
+	}
+	return conflicts
+}
+
+// claim reserves the normalized car's plate for the given lot and slot, or records and returns
+// a conflict if it is already parked elsewhere; enteredAs is the car as typed at the gate.
+// Lots call it while holding their own lock, so the registry must never call back into a lot.
+func (r *PlateRegistry) claim(car, enteredAs Car, lot *ParkingLot, slotID int, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, exists := r.plates[car.Plate]; exists {
+		conflict := PlateConflict{
+			Plate:        car.Plate,
+			ParkedCar:    existing.car,
+			ParkedLot:    existing.lot,
+			ParkedSlotID: existing.slotID,
+			RejectedCar:  enteredAs,
+			AttemptedLot: lot,
+			DetectedAt:   now,
+		}
+		r.conflicts = append(r.conflicts, conflict)
+		return &DuplicatePlateError{Conflict: conflict}
+	}
+
+	r.plates[car.Plate] = plateEntry{car: car, lot: lot, slotID: slotID}
+	return nil
+}
+
+// recordConflict keeps a duplicate detected inside a single lot
+func (r *PlateRegistry) recordConflict(conflict PlateConflict) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.conflicts = append(r.conflicts, conflict)
+}
+
+// release frees the plate when it leaves the lot that claimed it
+func (r *PlateRegistry) release(plateNumber string, lot *ParkingLot) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if existing, exists := r.plates[plateNumber]; exists && existing.lot == lot {
+		delete(r.plates, plateNumber)
+	}
+}
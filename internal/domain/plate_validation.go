@@ -0,0 +1,204 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ErrInvalidPlate is wrapped by every PlateValidationError. It wraps ErrInvalidCar, so
+// callers already checking for invalid cars also catch rejected plates.
+var ErrInvalidPlate = fmt.Errorf("%w: licence plate is not valid", ErrInvalidCar)
+
+// PlateValidationError explains why a plate failed validation
+type PlateValidationError struct {
+	Plate  string
+	Reason string
+}
+
+func (e *PlateValidationError) Error() string {
+	return fmt.Sprintf("%s: %s: %s", ErrInvalidPlate, e.Plate, e.Reason)
+}
+
+// Unwrap lets callers match the error with errors.Is(err, ErrInvalidPlate)
+func (e *PlateValidationError) Unwrap() error {
+	return ErrInvalidPlate
+}
+
+// PlateValidator checks a normalized plate, returning a *PlateValidationError when it is not valid
+type PlateValidator interface {
+	Validate(plate string) error
+}
+
+// PlateRule is the plate format of one jurisdiction: a pattern every plate must match and,
+// where the jurisdiction uses one, a check character computed from the rest of the plate
+type PlateRule struct {
+	Region   string
+	Pattern  *regexp.Regexp
+	Checksum func(plate string) error // Optional; returns why the check character is wrong
+}
+
+// Validate checks the plate against the rule
+func (r PlateRule) Validate(plate string) error {
+	if !r.Pattern.MatchString(plate) {
+		return &PlateValidationError{Plate: plate, Reason: fmt.Sprintf("does not match the %s plate format", r.Region)}
+	}
+	if r.Checksum != nil {
+		if err := r.Checksum(plate); err != nil {
+			return &PlateValidationError{Plate: plate, Reason: fmt.Sprintf("%s plate %v", r.Region, err)}
+		}
+	}
+	return nil
+}
+
+// PlateRuleSet accepts a plate valid under any of its rules, e.g. a lot near a border that
+// sees plates from two jurisdictions
+type PlateRuleSet []PlateRule
+
+// Validate accepts the plate if any rule does. When every rule fails, the reason given is
+// from a rule whose format matched, since a bad checksum says more than a format mismatch.
+func (rs PlateRuleSet) Validate(plate string) error {
+	var formatErr, checksumErr error
+	for _, rule := range rs {
+		err := rule.Validate(plate)
+		if err == nil {
+			return nil
+		}
+		if rule.Pattern.MatchString(plate) {
+			checksumErr = err
+		} else if formatErr == nil {
+			formatErr = err
+		}
+	}
+	switch {
+	case checksumErr != nil:
+		return checksumErr
+	case len(rs) > 1:
+		regions := make([]string, len(rs))
+		for i, rule := range rs {
+			regions[i] = rule.Region
+		}
+		return &PlateValidationError{Plate: plate, Reason: "does not match any accepted format (" + strings.Join(regions, ", ") + ")"}
+	case formatErr != nil:
+		return formatErr
+	}
+	return &PlateValidationError{Plate: plate, Reason: "no plate formats are accepted"}
+}
+
+// Plate rules for common jurisdictions. Plates are validated after NormalizePlate, so the
+// patterns have no spaces or dashes.
+var (
+	// IndiaPlates covers state series such as MH12AB1234 and the BH series such as 22BH1234AA
+	IndiaPlates = PlateRule{
+		Region:  "India",
+		Pattern: regexp.MustCompile(`^([A-Z]{2}[0-9]{1,2}[A-Z]{0,3}[0-9]{1,4}|[0-9]{2}BH[0-9]{4}[A-Z]{1,2})$`),
+	}
+
+	// UKPlates covers the current format, e.g. AB12CDE
+	UKPlates = PlateRule{
+		Region:  "UK",
+		Pattern: regexp.MustCompile(`^[A-Z]{2}[0-9]{2}[A-Z]{3}$`),
+	}
+
+	// CaliforniaPlates covers the standard passenger format, e.g. 7ABC123
+	CaliforniaPlates = PlateRule{
+		Region:  "California",
+		Pattern: regexp.MustCompile(`^[0-9][A-Z]{3}[0-9]{3}$`),
+	}
+
+	// SingaporePlates covers private cars, e.g. SBS3229P, whose final letter is a checksum
+	SingaporePlates = PlateRule{
+		Region:   "Singapore",
+		Pattern:  regexp.MustCompile(`^S[A-Z]{0,2}[0-9]{1,4}[A-Z]$`),
+		Checksum: singaporeChecksum,
+	}
+)
+
+// singaporeChecksum verifies the check letter of a Singapore plate. The last two prefix letters
+// (A=1 … Z=26, a missing letter counting as 0) and the digits padded to four are weighted
+// 9,4,5,4,3,2; the sum modulo 19 selects the letter.
+func singaporeChecksum(plate string) error {
+	const checkLetters = "AZYXUTSRPMLKJHGEDCB"
+	weights := []int{9, 4, 5, 4, 3, 2}
+
+	body, check := plate[:len(plate)-1], plate[len(plate)-1]
+	digitsAt := strings.IndexAny(body, "0123456789")
+	letters, digits := body[:digitsAt], body[digitsAt:]
+
+	values := make([]int, 0, 6)
+	letters = strings.Repeat("@", max(0, 2-len(letters))) + letters // '@' is 'A'-1, i.e. 0
+	for _, c := range letters[len(letters)-2:] {
+		values = append(values, int(c-'@'))
+	}
+	digits = strings.Repeat("0", 4-len(digits)) + digits
+	for _, c := range digits {
+		values = append(values, int(c-'0'))
+	}
+
+	sum := 0
+	for i, v := range values {
+		sum += v * weights[i]
+	}
+	if want := checkLetters[sum%19]; check != want {
+		return fmt.Errorf("has check letter %c, expected %c", check, want)
+	}
+	return nil
+}
+
+// PlateValidationMode selects what a lot does with a plate that fails validation
+type PlateValidationMode int
+
+const (
+	RejectInvalidPlates PlateValidationMode = iota // Refuse to park the car
+	FlagInvalidPlates                              // Park the car but report the plate to the police
+)
+
+// WithPlateValidation validates every plate parked in the lot
+func WithPlateValidation(validator PlateValidator, mode PlateValidationMode) LotOption {
+	return func(p *ParkingLot) {
+		p.plateValidator = validator
+		p.plateValidationMode = mode
+	}
+}
+
+// PlateRejection records a car turned away because of its plate
+type PlateRejection struct {
+	Car        Car // As entered at the gate
+	Reason     string
+	RejectedAt time.Time
+}
+
+// GetPlateRejections returns the cars turned away for invalid plates
+func (p *ParkingLot) GetPlateRejections() []PlateRejection {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	rejections := make([]PlateRejection, len(p.plateRejections))
+	copy(rejections, p.plateRejections)
+	return rejections
+}
+
+// validatePlate applies the lot's plate validation to a normalized car. It returns the reason
+// to flag the plate for, or an error if the car must be turned away. Bicycles carry a rack tag
+// rather than a plate and are not validated. The caller must hold p.mu for writing.
+func (p *ParkingLot) validatePlate(car, enteredAs Car) (string, error) {
+	if p.plateValidator == nil || car.Type == VehicleBicycle {
+		return "", nil
+	}
+	err := p.plateValidator.Validate(car.Plate)
+	if err == nil {
+		return "", nil
+	}
+
+	reason := err.Error()
+	var invalid *PlateValidationError
+	if errors.As(err, &invalid) {
+		reason = invalid.Reason
+	}
+	if p.plateValidationMode == FlagInvalidPlates {
+		return reason, nil
+	}
+	p.plateRejections = append(p.plateRejections, PlateRejection{Car: enteredAs, Reason: reason, RejectedAt: p.clock.Now()})
+	return "", err
+}
@@ -1,6 +1,9 @@
 package domain
 
-import "time"
+import (
+    "fmt"
+    "time"
+)
 
 // PoliceDepartment represents law enforcement investigation capabilities
 type PoliceDepartment struct {
@@ -16,126 +19,137 @@ func NewPoliceDepartment(name string) *PoliceDepartment {
 
 // InvestigateWhiteCars finds all white cars across multiple lots for bomb threat investigation
 func (pd *PoliceDepartment) InvestigateWhiteCars(lots []*ParkingLot) []CarLocation {
-    var allWhiteCars []CarLocation
-    
-    for i, lot := range lots {
-        whiteCars := lot.FindCarsByColor("White")
-        for _, car := range whiteCars {
-            location := CarLocation{
-                Car:    car,
-                LotID:  i,
-                SlotID: lot.FindCar(car.Plate),
-            }
-            allWhiteCars = append(allWhiteCars, location)
+    return sweep(lots, NewQuery().Where(ColorIs("White")), func(lot *ParkingLot, info *CarParkingInfo) CarLocation {
+        return CarLocation{
+            Car:      info.Car,
+            LotID:    lot.GetID(),
+            LotName:  lot.GetName(),
+            SlotID:   info.SlotID,
+            Location: lot.locationOf(info),
         }
-    }
-    
-    return allWhiteCars
+    })
 }
 
 // CarLocation represents a car's location information for police investigations
 type CarLocation struct {
-    Car    Car
-    LotID  int
-    SlotID int
+    Car      Car
+    LotID    string
+    LotName  string
+    SlotID   int
+    Location Location // Garage, level, row and slot, for reports
 }
 
 //for use case-13
-// InvestigateBlueToyotas finds all blue Toyota cars with complete investigation details
-func (pd *PoliceDepartment) InvestigateBlueToyotas(lots []*ParkingLot, attendant *ParkingAttendant) []RobberyInvestigation {
-    var allBlueToyotas []RobberyInvestigation
-    
-    for i, lot := range lots {
-        blueToyotas := lot.FindCarsByMakeAndColor("Toyota", "Blue")
-        for _, car := range blueToyotas {
-            investigation := RobberyInvestigation{
-                Car:           car,
-                LotID:         i,
-                SlotID:        lot.FindCar(car.Plate),
-                AttendantName: attendant.GetName(),
-            }
-            allBlueToyotas = append(allBlueToyotas, investigation)
+// InvestigateBlueToyotas finds all blue Toyota cars with complete investigation details, naming
+// the attendant who actually parked each car. The attendant argument is no longer used and is
+// kept for existing callers.
+func (pd *PoliceDepartment) InvestigateBlueToyotas(lots []*ParkingLot, _ *ParkingAttendant) []RobberyInvestigation {
+    blueToyotas := NewQuery().Where(MakeIs("Toyota")).Where(ColorIs("Blue"))
+    return sweep(lots, blueToyotas, func(lot *ParkingLot, info *CarParkingInfo) RobberyInvestigation {
+        return RobberyInvestigation{
+            Car:           info.Car,
+            LotID:         lot.GetID(),
+            LotName:       lot.GetName(),
+            SlotID:        info.SlotID,
+            AttendantName: info.AttendantName,
+            Location:      lot.locationOf(info),
         }
-    }
-    
-    return allBlueToyotas
+    })
 }
 
 // RobberyInvestigation represents complete information for robbery case investigation
 type RobberyInvestigation struct {
     Car           Car
-    LotID         int
+    LotID         string
+    LotName       string
     SlotID        int
     AttendantName string
+    Location      Location
 }
 
 //use case- 14
 // InvestigateBMWCars finds all BMW cars for security enhancement purposes
 func (pd *PoliceDepartment) InvestigateBMWCars(lots []*ParkingLot) []SecurityInvestigation {
-    var allBMWCars []SecurityInvestigation
-    
-    for i, lot := range lots {
-        bmwCars := lot.FindCarsByMake("BMW")
-        for _, car := range bmwCars {
-            investigation := SecurityInvestigation{
-                Car:    car,
-                LotID:  i,
-                SlotID: lot.FindCar(car.Plate),
-            }
-            allBMWCars = append(allBMWCars, investigation)
+    return sweep(lots, NewQuery().Where(MakeIs("BMW")), func(lot *ParkingLot, info *CarParkingInfo) SecurityInvestigation {
+        return SecurityInvestigation{
+            Car:      info.Car,
+            LotID:    lot.GetID(),
+            LotName:  lot.GetName(),
+            SlotID:   info.SlotID,
+            Location: lot.locationOf(info),
         }
-    }
-    
-    return allBMWCars
+    })
 }
 
 // SecurityInvestigation represents information for security enhancement purposes
 type SecurityInvestigation struct {
-    Car    Car
-    LotID  int
-    SlotID int
+    Car      Car
+    LotID    string
+    LotName  string
+    SlotID   int
+    Location Location
 }
 
 //use case-15
 // InvestigateRecentlyParkedCars finds all cars parked within specified minutes for bomb threat investigation
 func (pd *PoliceDepartment) InvestigateRecentlyParkedCars(lots []*ParkingLot, minutes int) []BombThreatInvestigation {
-    var allRecentCars []BombThreatInvestigation
-    
-    for i, lot := range lots {
-        recentCars := lot.FindCarsParkedInLastMinutes(minutes)
-        for _, car := range recentCars {
-            investigation := BombThreatInvestigation{
-                Car:         car,
-                LotID:       i,
-                SlotID:      lot.FindCar(car.Plate),
-                ParkingTime: lot.GetParkingTime(car.Plate),
-            }
-            allRecentCars = append(allRecentCars, investigation)
+    recentlyParked := NewQuery().Where(ParkedWithin(time.Duration(minutes) * time.Minute))
+    return sweep(lots, recentlyParked, func(lot *ParkingLot, info *CarParkingInfo) BombThreatInvestigation {
+        return BombThreatInvestigation{
+            Car:         info.Car,
+            LotID:       lot.GetID(),
+            LotName:     lot.GetName(),
+            SlotID:      info.SlotID,
+            ParkingTime: info.ParkedAt,
+            Location:    lot.locationOf(info),
         }
-    }
-    
-    return allRecentCars
+    })
 }
 
 // BombThreatInvestigation represents information for bomb threat investigation
 type BombThreatInvestigation struct {
     Car         Car
-    LotID       int
+    LotID       string
+    LotName     string
     SlotID      int
     ParkingTime time.Time
+    Location    Location
 }
 
 //UC-16
-// InvestigateHandicapPermitFraud finds small handicap cars in specific rows for fraud investigation
+// InvestigateHandicapPermitFraud finds small handicap cars in specific rows for fraud investigation,
+// along with cars parked as handicap cars without a valid permit and, last, the cars lots turned
+// away for claiming accessible parking without one (with SlotID -1)
 func (pd *PoliceDepartment) InvestigateHandicapPermitFraud(lots []*ParkingLot, targetRows []string) []HandicapFraudInvestigation {
     var allFraudCars []HandicapFraudInvestigation
     
-    for i, lot := range lots {
-        suspiciousCars := lot.FindSmallHandicapCarsInRows(targetRows)
+    watchedRows := And(SizeIs(Small), HandicapIs(true), RowIn(targetRows...))
+    for _, lot := range lots {
+        suspiciousCars := lot.Query(NewQuery().Where(Or(watchedRows, PermitFlagged())))
         for _, carInfo := range suspiciousCars {
             investigation := HandicapFraudInvestigation{
-                CarInfo: carInfo,
-                LotID:   i,
+                CarInfo:  carInfo,
+                LotID:    lot.GetID(),
+                LotName:  lot.GetName(),
+                Location: lot.locationOf(&carInfo),
+                Reason:   carInfo.PermitFlag,
+            }
+            if investigation.Reason == "" {
+                investigation.Reason = "small handicap car in a watched row"
+            }
+            allFraudCars = append(allFraudCars, investigation)
+        }
+    }
+    
+    for _, lot := range lots {
+        for _, rejection := range lot.GetPermitRejections() {
+            investigation := HandicapFraudInvestigation{
+                CarInfo:    CarParkingInfo{Car: NormalizeCar(rejection.Car), EnteredAs: rejection.Car, SlotID: -1, IsHandicap: true},
+                LotID:      lot.GetID(),
+                LotName:    lot.GetName(),
+                Reason:     rejection.Reason,
+                Rejected:   true,
+                RejectedAt: rejection.RejectedAt,
             }
             allFraudCars = append(allFraudCars, investigation)
         }
@@ -146,21 +160,53 @@ func (pd *PoliceDepartment) InvestigateHandicapPermitFraud(lots []*ParkingLot, t
 
 // HandicapFraudInvestigation represents information for handicap permit fraud investigation
 type HandicapFraudInvestigation struct {
-    CarInfo CarParkingInfo
-    LotID   int
+    CarInfo    CarParkingInfo
+    LotID      string
+    LotName    string
+    Location   Location // Empty for a rejected car
+    Reason     string // Why the car is suspected
+    Rejected   bool // The car was turned away and never parked
+    RejectedAt time.Time
 }
 
 //UC-17
-// InvestigateFraudulentPlates gets all cars in a specific lot for plate fraud investigation
+// InvestigateFraudulentPlates gets all cars in a specific lot for plate fraud investigation,
+// followed by the cars the lot turned away for invalid plates (with SlotID -1)
 func (pd *PoliceDepartment) InvestigateFraudulentPlates(lot *ParkingLot) []PlateInvestigation {
     var allPlateInvestigations []PlateInvestigation
     
-    allCars := lot.GetAllParkedCars()
-    for _, car := range allCars {
+    registry := lot.GetPlateRegistry()
+    allCars := lot.Query(NewQuery())
+    for _, info := range allCars {
         investigation := PlateInvestigation{
-            Car:         car,
-            SlotID:      lot.FindCar(car.Plate),
-            ParkingTime: lot.GetParkingTime(car.Plate),
+            Car:         info.Car,
+            LotID:       lot.GetID(),
+            LotName:     lot.GetName(),
+            SlotID:      info.SlotID,
+            ParkingTime: info.ParkedAt,
+        }
+        if info.PlateFlag != "" {
+            investigation.SuspectReasons = append(investigation.SuspectReasons, info.PlateFlag)
+        }
+        if registry != nil {
+            investigation.CloneAttempts = registry.GetConflictsForPlate(info.Car.Plate)
+        }
+        if len(investigation.CloneAttempts) > 0 {
+            reason := fmt.Sprintf("plate presented by %d other car(s) while parked", len(investigation.CloneAttempts))
+            investigation.SuspectReasons = append(investigation.SuspectReasons, reason)
+        }
+        allPlateInvestigations = append(allPlateInvestigations, investigation)
+    }
+    
+    for _, rejection := range lot.GetPlateRejections() {
+        investigation := PlateInvestigation{
+            Car:            rejection.Car,
+            LotID:          lot.GetID(),
+            LotName:        lot.GetName(),
+            SlotID:         -1,
+            ParkingTime:    rejection.RejectedAt,
+            SuspectReasons: []string{rejection.Reason},
+            Rejected:       true,
         }
         allPlateInvestigations = append(allPlateInvestigations, investigation)
     }
@@ -170,8 +216,180 @@ func (pd *PoliceDepartment) InvestigateFraudulentPlates(lot *ParkingLot) []Plate
 
 // PlateInvestigation represents information for fraudulent plate number investigation
 type PlateInvestigation struct {
-    Car         Car
-    SlotID      int
-    ParkingTime time.Time
+    Car            Car
+    LotID          string
+    LotName        string
+    SlotID         int
+    ParkingTime    time.Time // When the car parked, or when it was turned away
+    CloneAttempts  []PlateConflict // Other cars that tried to park under the same plate
+    SuspectReasons []string // Why the plate looks fraudulent, empty if nothing is known
+    Rejected       bool // The car was turned away and never parked
 }
 
+// InvestigateClonedPlates lists every duplicate plate rejected by the registry together with
+// where the original car is parked
+func (pd *PoliceDepartment) InvestigateClonedPlates(registry *PlateRegistry) []ClonedPlateInvestigation {
+    var allClonedPlates []ClonedPlateInvestigation
+    
+    for _, conflict := range registry.GetConflicts() {
+        investigation := ClonedPlateInvestigation{
+            Plate:          conflict.Plate,
+            ParkedCar:      conflict.ParkedCar,
+            ParkedLotID:    conflict.ParkedLot.GetID(),
+            ParkedSlotID:   conflict.ParkedSlotID,
+            SuspectCar:     conflict.RejectedCar,
+            AttemptedLotID: conflict.AttemptedLot.GetID(),
+            DetectedAt:     conflict.DetectedAt,
+        }
+        allClonedPlates = append(allClonedPlates, investigation)
+    }
+    
+    return allClonedPlates
+}
+
+// ClonedPlateInvestigation represents a plate seen on two cars at once
+type ClonedPlateInvestigation struct {
+    Plate          string
+    ParkedCar      Car
+    ParkedLotID    string
+    ParkedSlotID   int
+    SuspectCar     Car
+    AttemptedLotID string
+    DetectedAt     time.Time
+}
+
+
+
+// InvestigateDepartedCars reads a lot's journal and lists every completed visit that overlaps
+// the period from..to, so cars that have already left can still be traced
+func (pd *PoliceDepartment) InvestigateDepartedCars(journalPath string, from, to time.Time) ([]DepartedCarInvestigation, error) {
+    records, err := ReadJournal(journalPath)
+    if err != nil {
+        return nil, err
+    }
+    
+    var allDepartedCars []DepartedCarInvestigation
+    arrivals := make(map[string]JournalRecord)
+    rows := make(map[string]string)
+    lotName := ""
+    
+    for _, record := range records {
+        if record.Type == JournalLotOpened {
+            lotName = record.LotName
+        }
+        if record.Car == nil {
+            continue
+        }
+        plate := record.Car.Plate
+        switch record.Type {
+        case JournalPark:
+            arrivals[plate] = record
+            delete(rows, plate)
+        case JournalRowAssigned:
+            rows[plate] = record.Row
+        case JournalUnpark:
+            arrival, exists := arrivals[plate]
+            if !exists {
+                continue
+            }
+            delete(arrivals, plate)
+            if arrival.Time.After(to) || record.Time.Before(from) {
+                continue // Visit outside the period
+            }
+            investigation := DepartedCarInvestigation{
+                Car:           *arrival.Car,
+                LotID:         arrival.LotID,
+                LotName:       lotName,
+                SlotID:        arrival.SlotID,
+                Row:           rows[plate],
+                EntryTime:     arrival.Time,
+                ExitTime:      record.Time,
+                AttendantName: arrival.AttendantName,
+            }
+            allDepartedCars = append(allDepartedCars, investigation)
+        }
+    }
+    
+    return allDepartedCars, nil
+}
+
+// DepartedCarInvestigation represents a completed visit reconstructed from a lot's journal
+type DepartedCarInvestigation struct {
+    Car           Car
+    LotID         string
+    LotName       string // Empty if the lot had no name when the journal was started
+    SlotID        int
+    Row           string
+    EntryTime     time.Time
+    ExitTime      time.Time
+    AttendantName string
+}
+
+// InvestigateVisitHistory searches completed visits, e.g. every white car present between
+// 14:00 and 16:00 yesterday, including cars that have since driven out
+func (pd *PoliceDepartment) InvestigateVisitHistory(store HistoryStore, filter VisitFilter) []Visit {
+    return store.Query(filter)
+}
+
+// Investigate runs a text query such as "color=White AND make IN (Toyota,BMW) AND parked_within=30m"
+// across the lots. See ParseQuery for the query syntax; syntax errors are *QuerySyntaxError values.
+func (pd *PoliceDepartment) Investigate(lots []*ParkingLot, query string) ([]InvestigationResult, error) {
+    parsed, err := ParseQuery(query)
+    if err != nil {
+        return nil, err
+    }
+    return pd.InvestigateQuery(lots, parsed), nil
+}
+
+// InvestigateQuery runs a query built in code across the lots
+func (pd *PoliceDepartment) InvestigateQuery(lots []*ParkingLot, query *Query) []InvestigationResult {
+    return sweep(lots, query, func(lot *ParkingLot, info *CarParkingInfo) InvestigationResult {
+        return InvestigationResult{
+            Car:           info.Car,
+            EnteredAs:     info.EnteredAs,
+            LotID:         lot.GetID(),
+            LotName:       lot.GetName(),
+            SlotID:        info.SlotID,
+            Row:           info.Row,
+            IsHandicap:    info.IsHandicap,
+            EntryTime:     info.ParkedAt,
+            AttendantName: info.AttendantName,
+            Location:      lot.locationOf(info),
+        }
+    })
+}
+
+// sweep runs the query over every lot and turns each match into a result. Each lot's index
+// is looked up once to size the results, which are then allocated once and filled straight
+// from the lots. result may run under the lot's read lock, so it may only use the lot's fixed
+// details, and must not keep info.
+func sweep[T any](lots []*ParkingLot, query *Query, result func(lot *ParkingLot, info *CarParkingInfo) T) []T {
+    plans := make([]queryPlan, len(lots))
+    total := 0
+    for i, lot := range lots {
+        plans[i] = lot.planQuery(query)
+        total += plans[i].found
+    }
+    
+    results := make([]T, 0, total)
+    for i, lot := range lots {
+        lot.eachPlanned(query, plans[i], func(info *CarParkingInfo) {
+            results = append(results, result(lot, info))
+        })
+    }
+    return results
+}
+
+// InvestigationResult represents a parked car found by a police query
+type InvestigationResult struct {
+    Car           Car // Normalized, see NormalizeCar
+    EnteredAs     Car // As typed at the gate
+    LotID         string
+    LotName       string
+    SlotID        int
+    Row           string
+    IsHandicap    bool
+    EntryTime     time.Time
+    AttendantName string
+    Location      Location // e.g. "Garage North, Level 2, Row B, Slot 14"
+}
@@ -0,0 +1,353 @@
+package domain
+
+import (
+	"path"
+	"sort"
+	"time"
+)
+
+// queryContext carries what predicates need besides the car itself
+type queryContext struct {
+	now time.Time // Current time on the lot's clock
+}
+
+// Predicate is a condition on a parked car. Predicates are built with the constructors
+// below and combined with And, Or and Not.
+type Predicate struct {
+	match  func(info CarParkingInfo, ctx queryContext) bool
+	lookup indexLookup // Narrows the cars to check using the lot's index, nil to scan every car
+	exact  bool        // The lookup returns exactly the matching cars, so they need no checking
+}
+
+// Matches reports whether a parked car satisfies the predicate at the given time
+func (pr Predicate) Matches(info CarParkingInfo, now time.Time) bool {
+	return pr.matches(info, queryContext{now: now})
+}
+
+func (pr Predicate) matches(info CarParkingInfo, ctx queryContext) bool {
+	if pr.match == nil {
+		return true // The zero Predicate matches everything
+	}
+	return pr.match(info, ctx)
+}
+
+// PlateMatches matches plates against a shell pattern, e.g. "MH12*" or "MH12AB??34".
+// The pattern is normalized like a plate, so "mh-12*" works too.
+func PlateMatches(pattern string) Predicate {
+	pattern = NormalizePlate(pattern)
+	return Predicate{match: func(info CarParkingInfo, ctx queryContext) bool {
+		matched, err := path.Match(pattern, info.Car.Plate)
+		return err == nil && matched
+	}, lookup: lookupPlate(pattern), exact: lookupPlate(pattern) != nil}
+}
+
+// MakeIs matches cars of the given make, or any alias of it
+func MakeIs(make string) Predicate {
+	make = NormalizeMake(make)
+	return Predicate{match: func(info CarParkingInfo, ctx queryContext) bool {
+		return info.Car.Make == make
+	}, lookup: func(p *ParkingLot) slotSet { return p.index.byMake[make] }, exact: true}
+}
+
+// ColorIs matches cars of the given color, or any alias of it
+func ColorIs(color string) Predicate {
+	color = NormalizeColor(color)
+	return Predicate{match: func(info CarParkingInfo, ctx queryContext) bool {
+		return info.Car.Color == color
+	}, lookup: func(p *ParkingLot) slotSet { return p.index.byColor[color] }, exact: true}
+}
+
+// SizeIs matches cars of the given size
+func SizeIs(size CarSize) Predicate {
+	return Predicate{match: func(info CarParkingInfo, ctx queryContext) bool {
+		return info.Car.Size == size
+	}, lookup: func(p *ParkingLot) slotSet { return p.index.bySize[size] }, exact: true}
+}
+
+// TypeIs matches vehicles of the given type
+func TypeIs(vehicleType VehicleType) Predicate {
+	return Predicate{match: func(info CarParkingInfo, ctx queryContext) bool {
+		return info.Car.Type == vehicleType
+	}, lookup: func(p *ParkingLot) slotSet { return p.index.byType[vehicleType] }, exact: true}
+}
+
+// RowIn matches cars parked in any of the given rows
+func RowIn(rows ...string) Predicate {
+	rowSet := make(map[string]bool, len(rows))
+	lookups := make([]indexLookup, 0, len(rows))
+	for _, row := range rows {
+		rowSet[row] = true
+		lookups = append(lookups, func(p *ParkingLot) slotSet { return p.index.byRow[row] })
+	}
+	return Predicate{match: func(info CarParkingInfo, ctx queryContext) bool {
+		return rowSet[info.Row]
+	}, lookup: lookupAny(lookups), exact: true}
+}
+
+// HandicapIs matches cars whose handicap designation equals isHandicap
+func HandicapIs(isHandicap bool) Predicate {
+	return Predicate{match: func(info CarParkingInfo, ctx queryContext) bool {
+		return info.IsHandicap == isHandicap
+	}}
+}
+
+// ElectricIs matches vehicles whose electric designation equals isElectric
+func ElectricIs(isElectric bool) Predicate {
+	return Predicate{match: func(info CarParkingInfo, ctx queryContext) bool {
+		return info.Car.IsElectric == isElectric
+	}}
+}
+
+// PermitFlagged matches cars parked as handicap cars without a valid permit
+func PermitFlagged() Predicate {
+	return Predicate{match: func(info CarParkingInfo, ctx queryContext) bool {
+		return info.PermitFlag != ""
+	}}
+}
+
+// AttendantIs matches cars parked by the named attendant
+func AttendantIs(name string) Predicate {
+	return Predicate{match: func(info CarParkingInfo, ctx queryContext) bool {
+		return info.AttendantName == name
+	}}
+}
+
+// ParkedBetween matches cars that entered between from and to inclusive
+func ParkedBetween(from, to time.Time) Predicate {
+	return Predicate{match: func(info CarParkingInfo, ctx queryContext) bool {
+		return !info.ParkedAt.Before(from) && !info.ParkedAt.After(to)
+	}}
+}
+
+// ParkedWithin matches cars that entered less than d ago
+func ParkedWithin(d time.Duration) Predicate {
+	return Predicate{match: func(info CarParkingInfo, ctx queryContext) bool {
+		return info.ParkedAt.After(ctx.now.Add(-d))
+	}}
+}
+
+// ParkedLongerThan matches cars that have been parked for more than d
+func ParkedLongerThan(d time.Duration) Predicate {
+	return Predicate{match: func(info CarParkingInfo, ctx queryContext) bool {
+		return ctx.now.Sub(info.ParkedAt) > d
+	}}
+}
+
+// And matches cars satisfying every predicate
+func And(predicates ...Predicate) Predicate {
+	return Predicate{match: func(info CarParkingInfo, ctx queryContext) bool {
+		for _, pr := range predicates {
+			if !pr.matches(info, ctx) {
+				return false
+			}
+		}
+		return true
+	}, lookup: lookupAll(lookupsOf(predicates)), exact: allExact(predicates)}
+}
+
+// Or matches cars satisfying at least one predicate
+func Or(predicates ...Predicate) Predicate {
+	return Predicate{match: func(info CarParkingInfo, ctx queryContext) bool {
+		for _, pr := range predicates {
+			if pr.matches(info, ctx) {
+				return true
+			}
+		}
+		return false
+	}, lookup: lookupAny(lookupsOf(predicates)), exact: allExact(predicates)}
+}
+
+// Not matches cars that do not satisfy the predicate
+func Not(predicate Predicate) Predicate {
+	return Predicate{match: func(info CarParkingInfo, ctx queryContext) bool {
+		return !predicate.matches(info, ctx)
+	}}
+}
+
+func lookupsOf(predicates []Predicate) []indexLookup {
+	lookups := make([]indexLookup, len(predicates))
+	for i, pr := range predicates {
+		lookups[i] = pr.lookup
+	}
+	return lookups
+}
+
+// allExact reports whether every predicate is answered exactly by the index
+func allExact(predicates []Predicate) bool {
+	for _, pr := range predicates {
+		if !pr.exact {
+			return false
+		}
+	}
+	return true
+}
+
+// SortField selects the order of query results
+type SortField int
+
+const (
+	SortBySlot      SortField = iota // Default order
+	SortByEntryTime                  // Oldest arrival first
+	SortByPlate
+	SortByDuration // Shortest stay first
+)
+
+// Query selects parked cars from a lot
+type Query struct {
+	where      []Predicate
+	sortBy     SortField
+	descending bool
+	limit      int
+}
+
+// NewQuery creates a query matching every parked car, ordered by slot
+func NewQuery() *Query {
+	return &Query{}
+}
+
+// Where adds a condition; a car must satisfy every condition added
+func (q *Query) Where(predicate Predicate) *Query {
+	q.where = append(q.where, predicate)
+	return q
+}
+
+// SortBy orders the results by the given field
+func (q *Query) SortBy(field SortField, descending bool) *Query {
+	q.sortBy = field
+	q.descending = descending
+	return q
+}
+
+// Limit caps the number of results; zero means no limit
+func (q *Query) Limit(n int) *Query {
+	q.limit = n
+	return q
+}
+
+// Query returns the parked cars matching q
+func (p *ParkingLot) Query(q *Query) []CarParkingInfo {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.query(q, queryContext{now: p.clock.Now()})
+}
+
+// queryPlan is one lot's part of a query answered in two passes, as by a police sweep: the
+// first finds the cars that can match so results across lots can be sized, the second reads them
+type queryPlan struct {
+	candidates slotSet          // Slots whose cars may match, possibly shared with the index
+	changes    uint64           // The lot's change count when candidates were looked up
+	found      int              // Number of candidates, or of matches
+	scanned    bool             // The query cannot use the index, so the first pass ran it whole
+	matches    []CarParkingInfo // Cars found by the first pass when scanned
+}
+
+// planQuery runs the first pass of q: the index lookup, or the whole query when it has none
+// or is sorted or limited
+func (p *ParkingLot) planQuery(q *Query) queryPlan {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	where := And(q.where...)
+	if where.lookup == nil || q.limit > 0 || q.sortBy != SortBySlot || q.descending {
+		matches := p.query(q, queryContext{now: p.clock.Now()})
+		return queryPlan{found: len(matches), scanned: true, matches: matches}
+	}
+	candidates := where.lookup(p)
+	return queryPlan{candidates: candidates, changes: p.changes, found: candidates.count()}
+}
+
+// eachPlanned calls fn for every car the plan finds that still matches q, in slot order. If the
+// lot changed between the passes the index is looked up again, so fn only sees cars parked now.
+// fn may run under the lot's read lock and must not lock the lot again, nor keep info.
+func (p *ParkingLot) eachPlanned(q *Query, plan queryPlan, fn func(info *CarParkingInfo)) {
+	if plan.scanned {
+		for i := range plan.matches {
+			fn(&plan.matches[i])
+		}
+		return
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	ctx := queryContext{now: p.clock.Now()}
+	where := And(q.where...)
+	candidates := plan.candidates
+	if plan.changes != p.changes {
+		candidates = where.lookup(p)
+	}
+	candidates.each(func(id int) {
+		if info := &p.slotInfo[id]; where.exact || where.matches(*info, ctx) {
+			fn(info)
+		}
+	})
+}
+
+// query evaluates q over the parked cars in slot order, using the lot's index when a condition
+// allows it. The caller must hold p.mu.
+func (p *ParkingLot) query(q *Query, ctx queryContext) []CarParkingInfo {
+	where := And(q.where...)
+	var results []CarParkingInfo
+	if where.lookup != nil {
+		candidates := where.lookup(p)
+		results = make([]CarParkingInfo, 0, candidates.count())
+		candidates.each(func(id int) {
+			if info := p.slotInfo[id]; where.exact || where.matches(info, ctx) {
+				results = append(results, info)
+			}
+		})
+	} else {
+		results = p.scan(where, ctx)
+	}
+
+	q.sort(results, ctx)
+	if q.limit > 0 && len(results) > q.limit {
+		results = results[:q.limit]
+	}
+	return results
+}
+
+// scan checks every parked car in slot order. The caller must hold p.mu.
+func (p *ParkingLot) scan(where Predicate, ctx queryContext) []CarParkingInfo {
+	var results []CarParkingInfo
+	for _, slot := range p.slots {
+		if !slot.IsOccupied() || slot.overflow {
+			continue
+		}
+		if info := p.slotInfo[slot.ID]; where.matches(info, ctx) {
+			results = append(results, info)
+		}
+	}
+	return results
+}
+
+// sort orders results by the query's sort field, keeping slot order for ties
+func (q *Query) sort(results []CarParkingInfo, ctx queryContext) {
+	if q.sortBy == SortBySlot && !q.descending {
+		return // Results are gathered in slot order
+	}
+	less := func(a, b CarParkingInfo) bool {
+		switch q.sortBy {
+		case SortByEntryTime:
+			return a.ParkedAt.Before(b.ParkedAt)
+		case SortByPlate:
+			return a.Car.Plate < b.Car.Plate
+		case SortByDuration:
+			return ctx.now.Sub(a.ParkedAt) < ctx.now.Sub(b.ParkedAt)
+		default:
+			return a.SlotID < b.SlotID
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if q.descending {
+			return less(results[j], results[i])
+		}
+		return less(results[i], results[j])
+	})
+}
+
+// carsOf returns the cars of the query results
+func carsOf(results []CarParkingInfo) []Car {
+	var cars []Car
+	for _, info := range results {
+		cars = append(cars, info.Car)
+	}
+	return cars
+}
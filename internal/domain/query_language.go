@@ -0,0 +1,366 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ErrInvalidQuery is wrapped by every QuerySyntaxError
+var ErrInvalidQuery = errors.New("invalid query")
+
+// QuerySyntaxError reports a problem in a text query and where it was found
+type QuerySyntaxError struct {
+	Pos     int // Byte offset into the query text, starting at 0
+	Message string
+}
+
+func (e *QuerySyntaxError) Error() string {
+	return fmt.Sprintf("invalid query at position %d: %s", e.Pos, e.Message)
+}
+
+// Unwrap lets callers match the error with errors.Is(err, ErrInvalidQuery)
+func (e *QuerySyntaxError) Unwrap() error {
+	return ErrInvalidQuery
+}
+
+// queryField describes a field that can appear in a text query
+type queryField struct {
+	allowIn bool                                  // Whether "field IN (a,b)" is accepted
+	build   func(value string) (Predicate, error) // Predicate for "field = value"
+}
+
+var queryFields = map[string]queryField{
+	"color":     {allowIn: true, build: func(v string) (Predicate, error) { return ColorIs(v), nil }},
+	"make":      {allowIn: true, build: func(v string) (Predicate, error) { return MakeIs(v), nil }},
+	"plate":     {allowIn: true, build: func(v string) (Predicate, error) { return PlateMatches(v), nil }},
+	"row":       {allowIn: true, build: func(v string) (Predicate, error) { return RowIn(v), nil }},
+	"attendant": {allowIn: true, build: func(v string) (Predicate, error) { return AttendantIs(v), nil }},
+	"size":      {allowIn: true, build: parseSizeValue},
+	"type":      {allowIn: true, build: parseTypeValue},
+	"handicap": {build: func(v string) (Predicate, error) {
+		isHandicap, err := strconv.ParseBool(v)
+		if err != nil {
+			return Predicate{}, fmt.Errorf("expected true or false, got %q", v)
+		}
+		return HandicapIs(isHandicap), nil
+	}},
+	"electric": {build: func(v string) (Predicate, error) {
+		isElectric, err := strconv.ParseBool(v)
+		if err != nil {
+			return Predicate{}, fmt.Errorf("expected true or false, got %q", v)
+		}
+		return ElectricIs(isElectric), nil
+	}},
+	"parked_within": {build: func(v string) (Predicate, error) {
+		d, err := parseQueryDuration(v)
+		return ParkedWithin(d), err
+	}},
+	"parked_longer_than": {build: func(v string) (Predicate, error) {
+		d, err := parseQueryDuration(v)
+		return ParkedLongerThan(d), err
+	}},
+}
+
+func parseSizeValue(v string) (Predicate, error) {
+	for _, size := range []CarSize{Small, Medium, Large} {
+		if strings.EqualFold(v, size.String()) {
+			return SizeIs(size), nil
+		}
+	}
+	return Predicate{}, fmt.Errorf("expected Small, Medium or Large, got %q", v)
+}
+
+func parseTypeValue(v string) (Predicate, error) {
+	for _, vehicleType := range VehicleTypes {
+		if strings.EqualFold(v, vehicleType.String()) {
+			return TypeIs(vehicleType), nil
+		}
+	}
+	return Predicate{}, fmt.Errorf("expected Car, Motorcycle, Bicycle, Van, Bus or Truck, got %q", v)
+}
+
+func parseQueryDuration(v string) (time.Duration, error) {
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("expected a duration such as 30m or 2h, got %q", v)
+	}
+	return d, nil
+}
+
+// queryFieldNames lists the known fields for error messages
+func queryFieldNames() string {
+	names := make([]string, 0, len(queryFields))
+	for name := range queryFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// ParseQuery parses a text query such as
+//
+//	color=White AND make IN (Toyota,BMW) AND parked_within=30m AND row IN (B,D)
+//
+// Conditions are field=value, field!=value or field IN (v1,v2,...), combined with AND, OR, NOT
+// and parentheses; AND binds tighter than OR. Keywords and field names are case-insensitive;
+// colors, makes and plates are normalized like parked cars, other values are matched exactly.
+// Values containing spaces or punctuation can be double-quoted. Plates accept shell patterns
+// such as MH12*. Errors are *QuerySyntaxError values pointing at the problem.
+func ParseQuery(text string) (*Query, error) {
+	tokens, err := lexQuery(text)
+	if err != nil {
+		return nil, err
+	}
+	parser := &queryParser{tokens: tokens}
+	if parser.peek().kind == tokenEOF {
+		return nil, &QuerySyntaxError{Pos: 0, Message: "query is empty"}
+	}
+	predicate, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := parser.peek(); tok.kind != tokenEOF {
+		return nil, &QuerySyntaxError{Pos: tok.pos, Message: fmt.Sprintf("unexpected %s, expected AND or OR", tok)}
+	}
+	return NewQuery().Where(predicate), nil
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenLParen
+	tokenRParen
+	tokenComma
+	tokenEq
+	tokenNotEq
+)
+
+type queryToken struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t queryToken) String() string {
+	if t.kind == tokenEOF {
+		return "end of query"
+	}
+	return strconv.Quote(t.text)
+}
+
+// isKeyword reports whether the token is the given keyword, ignoring case
+func (t queryToken) isKeyword(keyword string) bool {
+	return t.kind == tokenWord && strings.EqualFold(t.text, keyword)
+}
+
+// lexQuery splits a text query into tokens
+func lexQuery(text string) ([]queryToken, error) {
+	var tokens []queryToken
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+		case c == '(':
+			tokens = append(tokens, queryToken{kind: tokenLParen, text: "(", pos: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, queryToken{kind: tokenRParen, text: ")", pos: i})
+			i++
+		case c == ',':
+			tokens = append(tokens, queryToken{kind: tokenComma, text: ",", pos: i})
+			i++
+		case c == '=':
+			tokens = append(tokens, queryToken{kind: tokenEq, text: "=", pos: i})
+			i++
+		case c == '!':
+			if i+1 >= len(text) || text[i+1] != '=' {
+				return nil, &QuerySyntaxError{Pos: i, Message: `expected "!="`}
+			}
+			tokens = append(tokens, queryToken{kind: tokenNotEq, text: "!=", pos: i})
+			i += 2
+		case c == '"':
+			end := strings.IndexByte(text[i+1:], '"')
+			if end < 0 {
+				return nil, &QuerySyntaxError{Pos: i, Message: "unterminated quoted value"}
+			}
+			tokens = append(tokens, queryToken{kind: tokenString, text: text[i+1 : i+1+end], pos: i})
+			i += end + 2
+		default:
+			start := i
+			for i < len(text) && !unicode.IsSpace(rune(text[i])) && !strings.ContainsRune(`(),=!"`, rune(text[i])) {
+				i++
+			}
+			tokens = append(tokens, queryToken{kind: tokenWord, text: text[start:i], pos: start})
+		}
+	}
+	return append(tokens, queryToken{kind: tokenEOF, pos: len(text)}), nil
+}
+
+// queryParser is a recursive descent parser over the tokens of a text query
+type queryParser struct {
+	tokens []queryToken
+	next   int
+}
+
+func (qp *queryParser) peek() queryToken {
+	return qp.tokens[qp.next]
+}
+
+func (qp *queryParser) advance() queryToken {
+	tok := qp.tokens[qp.next]
+	if tok.kind != tokenEOF {
+		qp.next++
+	}
+	return tok
+}
+
+func (qp *queryParser) expect(kind tokenKind, what string) (queryToken, error) {
+	tok := qp.advance()
+	if tok.kind != kind {
+		return tok, &QuerySyntaxError{Pos: tok.pos, Message: fmt.Sprintf("expected %s, got %s", what, tok)}
+	}
+	return tok, nil
+}
+
+// parseOr parses: and { OR and }
+func (qp *queryParser) parseOr() (Predicate, error) {
+	first, err := qp.parseAnd()
+	if err != nil {
+		return Predicate{}, err
+	}
+	terms := []Predicate{first}
+	for qp.peek().isKeyword("OR") {
+		qp.advance()
+		term, err := qp.parseAnd()
+		if err != nil {
+			return Predicate{}, err
+		}
+		terms = append(terms, term)
+	}
+	if len(terms) == 1 {
+		return first, nil
+	}
+	return Or(terms...), nil
+}
+
+// parseAnd parses: unary { AND unary }
+func (qp *queryParser) parseAnd() (Predicate, error) {
+	first, err := qp.parseUnary()
+	if err != nil {
+		return Predicate{}, err
+	}
+	terms := []Predicate{first}
+	for qp.peek().isKeyword("AND") {
+		qp.advance()
+		term, err := qp.parseUnary()
+		if err != nil {
+			return Predicate{}, err
+		}
+		terms = append(terms, term)
+	}
+	if len(terms) == 1 {
+		return first, nil
+	}
+	return And(terms...), nil
+}
+
+// parseUnary parses: NOT unary | ( or ) | condition
+func (qp *queryParser) parseUnary() (Predicate, error) {
+	tok := qp.peek()
+	switch {
+	case tok.isKeyword("NOT"):
+		qp.advance()
+		inner, err := qp.parseUnary()
+		if err != nil {
+			return Predicate{}, err
+		}
+		return Not(inner), nil
+	case tok.kind == tokenLParen:
+		qp.advance()
+		inner, err := qp.parseOr()
+		if err != nil {
+			return Predicate{}, err
+		}
+		if _, err := qp.expect(tokenRParen, `")"`); err != nil {
+			return Predicate{}, err
+		}
+		return inner, nil
+	}
+	return qp.parseCondition()
+}
+
+// parseCondition parses: field = value | field != value | field IN ( value { , value } )
+func (qp *queryParser) parseCondition() (Predicate, error) {
+	fieldTok, err := qp.expect(tokenWord, "a field name")
+	if err != nil {
+		return Predicate{}, err
+	}
+	name := strings.ToLower(fieldTok.text)
+	field, known := queryFields[name]
+	if !known {
+		return Predicate{}, &QuerySyntaxError{
+			Pos:     fieldTok.pos,
+			Message: fmt.Sprintf("unknown field %q, expected one of %s", fieldTok.text, queryFieldNames()),
+		}
+	}
+
+	op := qp.advance()
+	switch {
+	case op.kind == tokenEq:
+		return qp.parseValue(field)
+	case op.kind == tokenNotEq:
+		predicate, err := qp.parseValue(field)
+		return Not(predicate), err
+	case op.isKeyword("IN"):
+		if !field.allowIn {
+			return Predicate{}, &QuerySyntaxError{Pos: op.pos, Message: fmt.Sprintf("field %s does not support IN", name)}
+		}
+		return qp.parseValueList(field)
+	}
+	return Predicate{}, &QuerySyntaxError{Pos: op.pos, Message: fmt.Sprintf(`expected "=", "!=" or IN after %s, got %s`, name, op)}
+}
+
+// parseValue parses a single value for field
+func (qp *queryParser) parseValue(field queryField) (Predicate, error) {
+	tok := qp.advance()
+	if tok.kind != tokenWord && tok.kind != tokenString {
+		return Predicate{}, &QuerySyntaxError{Pos: tok.pos, Message: fmt.Sprintf("expected a value, got %s", tok)}
+	}
+	predicate, err := field.build(tok.text)
+	if err != nil {
+		return Predicate{}, &QuerySyntaxError{Pos: tok.pos, Message: err.Error()}
+	}
+	return predicate, nil
+}
+
+// parseValueList parses ( value { , value } ) for field
+func (qp *queryParser) parseValueList(field queryField) (Predicate, error) {
+	if _, err := qp.expect(tokenLParen, `"(" to start the value list`); err != nil {
+		return Predicate{}, err
+	}
+	var options []Predicate
+	for {
+		option, err := qp.parseValue(field)
+		if err != nil {
+			return Predicate{}, err
+		}
+		options = append(options, option)
+
+		tok := qp.advance()
+		if tok.kind == tokenRParen {
+			return Or(options...), nil
+		}
+		if tok.kind != tokenComma {
+			return Predicate{}, &QuerySyntaxError{Pos: tok.pos, Message: fmt.Sprintf(`expected "," or ")", got %s`, tok)}
+		}
+	}
+}
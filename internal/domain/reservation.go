@@ -0,0 +1,421 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Reservation errors
+var (
+	ErrInvalidReservation     = errors.New("invalid reservation")
+	ErrReservationUnavailable = errors.New("no slot of that type is free to book for the window")
+	ErrUnknownReservation     = errors.New("reservation does not exist")
+	ErrAlreadyReserved        = errors.New("plate already holds a reservation for the window")
+)
+
+// DefaultReservationGrace is how long a reserved slot is held past the start of its window
+// for lots that do not set their own grace period
+const DefaultReservationGrace = 15 * time.Minute
+
+// ReservationStatus is where a reservation is in its life
+type ReservationStatus int
+
+const (
+	ReservationBooked    ReservationStatus = iota // Waiting for the car, holding a slot once the window opens
+	ReservationHonoured                           // The car arrived and was parked
+	ReservationNoShow                             // The car did not arrive within the grace period
+	ReservationCancelled                          // Cancelled before the car arrived
+)
+
+// String returns string representation of ReservationStatus
+func (s ReservationStatus) String() string {
+	switch s {
+	case ReservationBooked:
+		return "Booked"
+	case ReservationHonoured:
+		return "Honoured"
+	case ReservationNoShow:
+		return "No-show"
+	case ReservationCancelled:
+		return "Cancelled"
+	default:
+		return "Unknown"
+	}
+}
+
+// Reservation books a slot of one type in a lot for a plate over a time window. From the
+// start of the window the lot holds a free slot of that type for the plate, so other cars
+// cannot take it, until the car arrives or the grace period runs out.
+type Reservation struct {
+	ID       string            `json:"id"`
+	Plate    string            `json:"plate"`
+	SlotSize SlotSize          `json:"slot_size"`
+	From     time.Time         `json:"from"`
+	To       time.Time         `json:"to"`
+	Status   ReservationStatus `json:"status"`
+	SlotID   int               `json:"slot_id"` // Slot held or, once honoured, the slot the car was parked in; -1 for none
+}
+
+// deadline returns when an unhonoured reservation stops holding its slot
+func (r Reservation) deadline(grace time.Duration) time.Time {
+	if end := r.From.Add(grace); end.Before(r.To) {
+		return end
+	}
+	return r.To
+}
+
+// overlaps reports whether the reservation's window overlaps from..to
+func (r Reservation) overlaps(from, to time.Time) bool {
+	return r.From.Before(to) && from.Before(r.To)
+}
+
+// WithReservationGrace sets how long a reserved slot is held past the start of its window
+// before the reservation is released as a no-show
+func WithReservationGrace(grace time.Duration) LotOption {
+	return func(p *ParkingLot) {
+		p.reservationGrace = grace
+	}
+}
+
+// WithReservationEarlyArrival lets a reserved car arriving up to margin before its window
+// opens honour the reservation. Cars arriving earlier park as walk-ins and keep the booking.
+func WithReservationEarlyArrival(margin time.Duration) LotOption {
+	return func(p *ParkingLot) {
+		p.reservationEarly = margin
+	}
+}
+
+// Reserve books a slot of the given type for the plate from..to. Bookings are limited to the
+// number of slots of the type, accessible slots aside, that are not booked for an overlapping
+// window. Windows that have already ended are refused.
+func (p *ParkingLot) Reserve(plateNumber string, slotSize SlotSize, from, to time.Time) (Reservation, error) {
+	plateNumber = NormalizePlate(plateNumber)
+	if plateNumber == "" || !to.After(from) {
+		return Reservation{}, fmt.Errorf("%w: needs a plate and a window ending after it starts", ErrInvalidReservation)
+	}
+	if !to.After(p.clock.Now()) {
+		return Reservation{}, fmt.Errorf("%w: window ended at %s", ErrInvalidReservation, to.Format(time.DateTime))
+	}
+
+	p.mu.Lock()
+	booked := 0
+	for _, r := range p.reservations {
+		if (r.Status != ReservationBooked && r.Status != ReservationHonoured) || !r.overlaps(from, to) {
+			continue
+		}
+		if r.Plate == plateNumber && r.Status == ReservationBooked {
+			p.mu.Unlock()
+			return Reservation{}, fmt.Errorf("%w: %s", ErrAlreadyReserved, r.ID)
+		}
+		if r.SlotSize == slotSize {
+			booked++
+		}
+	}
+	if booked >= p.bookableSlots(slotSize) {
+		p.mu.Unlock()
+		return Reservation{}, fmt.Errorf("%w: %s slot from %s", ErrReservationUnavailable, slotSize, from.Format(time.DateTime))
+	}
+
+	reservation := &Reservation{ID: newTicketID(), Plate: plateNumber, SlotSize: slotSize, From: from, To: to, SlotID: -1}
+	record := *reservation
+	if err := p.writeJournal(JournalRecord{Type: JournalReserved, LotID: p.id, Time: p.clock.Now(), Reservation: &record, SlotID: -1}); err != nil {
+		p.mu.Unlock()
+		return Reservation{}, err
+	}
+	p.reservations = append(p.reservations, reservation)
+	event, err := p.refreshReservations(p.clock.Now())
+	booking := *reservation
+	p.mu.Unlock()
+
+	p.notify(event)
+	return booking, err
+}
+
+// CancelReservation cancels a booked reservation and frees any slot it holds
+func (p *ParkingLot) CancelReservation(id string) error {
+	p.mu.Lock()
+	reservation := p.reservationByID(id)
+	if reservation == nil || reservation.Status != ReservationBooked {
+		p.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrUnknownReservation, id)
+	}
+	record := JournalRecord{Type: JournalReservationCancelled, LotID: p.id, Time: p.clock.Now(), ReservationID: id, SlotID: reservation.SlotID}
+	if err := p.writeJournal(record); err != nil {
+		p.mu.Unlock()
+		return err
+	}
+	p.releaseHold(reservation)
+	reservation.Status = ReservationCancelled
+	event, err := p.refreshReservations(p.clock.Now())
+	p.mu.Unlock()
+
+	p.notify(event)
+	return err
+}
+
+// GetReservation returns a reservation by ID
+func (p *ParkingLot) GetReservation(id string) (Reservation, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if reservation := p.reservationByID(id); reservation != nil {
+		return *reservation, true
+	}
+	return Reservation{}, false
+}
+
+// GetReservations returns every reservation made at the lot, in booking order
+func (p *ParkingLot) GetReservations() []Reservation {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	reservations := make([]Reservation, len(p.reservations))
+	for i, reservation := range p.reservations {
+		reservations[i] = *reservation
+	}
+	return reservations
+}
+
+// ProcessReservations holds slots for reservations whose window has opened and releases
+// no-shows whose grace period has run out, notifying observers if that fills or frees the
+// lot. The lot also does this whenever a car arrives or leaves or a reservation is made or
+// cancelled; getters such as GetAvailableSpaces report holds as of now without changing them.
+// An error is returned only if the change cannot be journaled, in which case nothing changes.
+func (p *ParkingLot) ProcessReservations() error {
+	p.mu.Lock()
+	event, err := p.refreshReservations(p.clock.Now())
+	p.mu.Unlock()
+
+	p.notify(event)
+	return err
+}
+
+// holdChange is a slot hold or a no-show that bringing reservations up to date would make
+type holdChange struct {
+	reservation *Reservation
+	slot        *Slot // Slot to hold, nil for a no-show
+}
+
+// planReservations returns the holds and no-shows bringing reservations up to date at now
+// would make, in booking order, without changing anything. The caller must hold p.mu.
+func (p *ParkingLot) planReservations(now time.Time) []holdChange {
+	var changes []holdChange
+	var released, claimed map[int]bool
+	for _, reservation := range p.reservations {
+		if reservation.Status != ReservationBooked {
+			continue
+		}
+		switch {
+		case !now.Before(reservation.deadline(p.reservationGrace)):
+			changes = append(changes, holdChange{reservation: reservation})
+			if reservation.SlotID != -1 {
+				if released == nil {
+					released = make(map[int]bool)
+				}
+				released[reservation.SlotID] = true
+			}
+		case !now.Before(reservation.From) && reservation.SlotID == -1:
+			if slot := p.slotToHold(reservation.SlotSize, released, claimed); slot != nil {
+				changes = append(changes, holdChange{reservation: reservation, slot: slot})
+				if claimed == nil {
+					claimed = make(map[int]bool)
+				}
+				claimed[slot.ID] = true
+			}
+		}
+	}
+	return changes
+}
+
+// refreshReservations brings slot holds up to date at now and returns the observer event
+// the change raises. The changes are journaled first; if that fails nothing changes. The
+// caller must hold p.mu for writing.
+func (p *ParkingLot) refreshReservations(now time.Time) (lotEvent, error) {
+	if len(p.reservations) == 0 {
+		return noEvent, nil
+	}
+	changes := p.planReservations(now)
+	held := p.held
+	var records []JournalRecord
+	for _, change := range changes {
+		record := JournalRecord{LotID: p.id, Time: now, ReservationID: change.reservation.ID}
+		if change.slot == nil {
+			record.Type, record.SlotID = JournalReservationNoShow, change.reservation.SlotID
+			if change.reservation.SlotID != -1 {
+				held--
+			}
+		} else {
+			record.Type, record.SlotID = JournalReservationHeld, change.slot.ID
+			held++
+		}
+		records = append(records, record)
+	}
+
+	event := noEvent
+	full := p.occupied+held == p.capacity
+	switch {
+	case full && !p.wasFull:
+		event = reservedFullEvent
+		records = append(records, JournalRecord{Type: JournalLotFull, LotID: p.id, Time: now})
+	case !full && p.wasFull:
+		event = spaceAvailableEvent
+		records = append(records, JournalRecord{Type: JournalSpaceAvailable, LotID: p.id, Time: now})
+	}
+	if len(records) == 0 {
+		return noEvent, nil
+	}
+	if err := p.writeJournal(records...); err != nil {
+		return noEvent, err
+	}
+
+	for _, change := range changes {
+		if change.slot == nil {
+			p.releaseHold(change.reservation)
+			change.reservation.Status = ReservationNoShow
+		} else {
+			p.hold(change.reservation, change.slot)
+		}
+	}
+	p.wasFull = full
+	return event, nil
+}
+
+// pendingHolds returns how bringing reservations up to date at now would change the number
+// of held slots of each size, negative where no-shows would release their slots, so getters
+// can report holds as of now under a read lock. The caller must hold p.mu.
+func (p *ParkingLot) pendingHolds(now time.Time) [numSlotSizes]int {
+	var changes [numSlotSizes]int
+	for _, change := range p.planReservations(now) {
+		switch {
+		case change.slot != nil:
+			changes[change.slot.Size]++
+		case change.reservation.SlotID != -1:
+			changes[p.slots[change.reservation.SlotID].Size]--
+		}
+	}
+	return changes
+}
+
+// freeAt returns the free slots of each size at now, less the slots reservations would hold
+// by then. The caller must hold p.mu.
+func (p *ParkingLot) freeAt(now time.Time) [numSlotSizes]int {
+	free := p.freeBySize
+	for size, change := range p.pendingHolds(now) {
+		free[size] -= change
+	}
+	return free
+}
+
+// heldAt returns the number of slots reservations hold at now. The caller must hold p.mu.
+func (p *ParkingLot) heldAt(now time.Time) int {
+	held := p.held
+	for _, change := range p.pendingHolds(now) {
+		held += change
+	}
+	return held
+}
+
+// reservedSlotFor returns the slot held for the plate's reservation if the car fits it, or
+// nil. The caller must hold p.mu.
+func (p *ParkingLot) reservedSlotFor(car Car) *Slot {
+	for _, reservation := range p.reservations {
+		if reservation.Plate == car.Plate && reservation.Status == ReservationBooked && reservation.SlotID != -1 {
+			if slot := p.slots[reservation.SlotID]; slot.Size.accepts(car) {
+				return slot
+			}
+		}
+	}
+	return nil
+}
+
+// reservationToHonour returns the plate's booked reservation a car parking at now honours, or
+// nil. Only arrivals from the window start, less the lot's early-arrival margin, honour it.
+// The caller must hold p.mu.
+func (p *ParkingLot) reservationToHonour(plateNumber string, now time.Time) *Reservation {
+	for _, reservation := range p.reservations {
+		if reservation.Plate == plateNumber && reservation.Status == ReservationBooked &&
+			!now.Before(reservation.From.Add(-p.reservationEarly)) && now.Before(reservation.To) {
+			return reservation
+		}
+	}
+	return nil
+}
+
+// honour marks a reservation as honoured by a car parked in slotID, freeing the slot it held
+// if the car was parked elsewhere. The caller must hold p.mu for writing.
+func (p *ParkingLot) honour(reservation *Reservation, slotID int) {
+	p.releaseHold(reservation)
+	reservation.Status = ReservationHonoured
+	reservation.SlotID = slotID
+}
+
+// hold keeps a free slot for a reservation. The caller must hold p.mu for writing.
+func (p *ParkingLot) hold(reservation *Reservation, slot *Slot) {
+	slot.reservation = reservation.ID
+	reservation.SlotID = slot.ID
+	p.freeBySize[slot.Size]--
+	p.held++
+}
+
+// releaseHold frees the slot a reservation holds, if any. The caller must hold p.mu for writing.
+func (p *ParkingLot) releaseHold(reservation *Reservation) {
+	if reservation.SlotID == -1 {
+		return
+	}
+	if slot := p.slots[reservation.SlotID]; slot.reservation == reservation.ID {
+		p.unhold(slot)
+	}
+	reservation.SlotID = -1
+}
+
+// unhold frees a held slot. The caller must hold p.mu for writing.
+func (p *ParkingLot) unhold(slot *Slot) {
+	slot.reservation = ""
+	p.freeBySize[slot.Size]++
+	p.held--
+}
+
+// slotToHold returns the lowest free slot of exactly the given size that is not accessible
+// and not held, treating released slots as no longer held and claimed ones as held. The
+// caller must hold p.mu.
+func (p *ParkingLot) slotToHold(slotSize SlotSize, released, claimed map[int]bool) *Slot {
+	for id := p.nextFree; id < len(p.slots); id++ {
+		slot := p.slots[id]
+		if slot.Size == slotSize && !slot.Accessible && !slot.IsOccupied() &&
+			(slot.reservation == "" || released[id]) && !claimed[id] {
+			return slot
+		}
+	}
+	return nil
+}
+
+// canHold reports whether a reservation for slotSize may hold the slot: it exists, has that
+// size, is not accessible, and is neither occupied nor held. The caller must hold p.mu.
+func (p *ParkingLot) canHold(slotID int, slotSize SlotSize) bool {
+	if slotID < 0 || slotID >= len(p.slots) {
+		return false
+	}
+	slot := p.slots[slotID]
+	return slot.Size == slotSize && !slot.Accessible && !slot.IsOccupied() && slot.reservation == ""
+}
+
+// bookableSlots returns the number of slots of the size reservations can hold. The caller
+// must hold p.mu.
+func (p *ParkingLot) bookableSlots(slotSize SlotSize) int {
+	count := 0
+	for _, slot := range p.slots {
+		if slot.Size == slotSize && !slot.Accessible {
+			count++
+		}
+	}
+	return count
+}
+
+// reservationByID returns the reservation with the given ID, or nil. The caller must hold p.mu.
+func (p *ParkingLot) reservationByID(id string) *Reservation {
+	for _, reservation := range p.reservations {
+		if reservation.ID == id {
+			return reservation
+		}
+	}
+	return nil
+}
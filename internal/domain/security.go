@@ -2,4 +2,10 @@ package domain
 
 type Security interface {
 	OnLotFull(message string)
-}
\ No newline at end of file
+}
+
+// DuplicatePlateObserver can be implemented by a Security observer to be alerted
+// when a plate that is already parked tries to enter a lot
+type DuplicatePlateObserver interface {
+	OnDuplicatePlate(conflict PlateConflict)
+}
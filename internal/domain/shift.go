@@ -0,0 +1,177 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Shift errors
+var (
+	ErrInvalidShift = errors.New("invalid shift")
+	ErrShiftOverlap = errors.New("shift overlaps another shift of the same attendant")
+	ErrNotOnDuty    = errors.New("attendant is not on duty at this lot")
+)
+
+// Shift is a block of time an attendant is on duty at some lots
+type Shift struct {
+	ID        string
+	Attendant string   // Attendant name, as given to NewParkingAttendant
+	LotIDs    []string // Lots the attendant works at during the shift
+	Start     time.Time
+	End       time.Time
+}
+
+// covers reports whether the shift puts its attendant on duty at the lot at the given time
+func (s Shift) covers(lotID string, at time.Time) bool {
+	if at.Before(s.Start) || !at.Before(s.End) {
+		return false
+	}
+	for _, id := range s.LotIDs {
+		if id == lotID {
+			return true
+		}
+	}
+	return false
+}
+
+// ShiftSchedule says which attendants are on duty at which lots. It can be shared by several
+// lots and is safe for concurrent use.
+type ShiftSchedule struct {
+	mu     sync.RWMutex
+	shifts []Shift // In start order
+}
+
+// NewShiftSchedule creates an empty schedule
+func NewShiftSchedule() *ShiftSchedule {
+	return &ShiftSchedule{}
+}
+
+// Add schedules a shift. A shift must end after it starts, cover at least one lot and not
+// overlap another shift of the same attendant.
+func (s *ShiftSchedule) Add(shift Shift) error {
+	if shift.ID == "" || shift.Attendant == "" || len(shift.LotIDs) == 0 || !shift.End.After(shift.Start) {
+		return fmt.Errorf("%w: %s needs an ID, an attendant, lots and an end after its start", ErrInvalidShift, shift.ID)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, other := range s.shifts {
+		if other.ID == shift.ID {
+			return fmt.Errorf("%w: duplicate ID %s", ErrInvalidShift, shift.ID)
+		}
+		if other.Attendant == shift.Attendant && shift.Start.Before(other.End) && other.Start.Before(shift.End) {
+			return fmt.Errorf("%w: %s and %s", ErrShiftOverlap, shift.ID, other.ID)
+		}
+	}
+	shift.LotIDs = append([]string(nil), shift.LotIDs...)
+	s.shifts = append(s.shifts, shift)
+	sort.SliceStable(s.shifts, func(i, j int) bool { return s.shifts[i].Start.Before(s.shifts[j].Start) })
+	return nil
+}
+
+// GetShifts returns every scheduled shift in start order
+func (s *ShiftSchedule) GetShifts() []Shift {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	shifts := make([]Shift, len(s.shifts))
+	copy(shifts, s.shifts)
+	return shifts
+}
+
+// OnDuty returns the attendants on duty at the lot at the given time
+func (s *ShiftSchedule) OnDuty(lotID string, at time.Time) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var attendants []string
+	for _, shift := range s.shifts {
+		if shift.covers(lotID, at) {
+			attendants = append(attendants, shift.Attendant)
+		}
+	}
+	return attendants
+}
+
+// ShiftAt returns the attendant's shift covering the lot at the given time
+func (s *ShiftSchedule) ShiftAt(attendant, lotID string, at time.Time) (Shift, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, shift := range s.shifts {
+		if shift.Attendant == attendant && shift.covers(lotID, at) {
+			return shift, true
+		}
+	}
+	return Shift{}, false
+}
+
+// WithShiftSchedule only lets attendants park and retrieve cars at the lot while the schedule
+// has them on duty there. Drivers parking themselves are not affected.
+func WithShiftSchedule(schedule *ShiftSchedule) LotOption {
+	return func(p *ParkingLot) {
+		p.shifts = schedule
+	}
+}
+
+// checkOnDuty returns an error wrapping ErrNotOnDuty if the named attendant is off duty at
+// the lot. The caller must hold p.mu.
+func (p *ParkingLot) checkOnDuty(attendant string, at time.Time) error {
+	if p.shifts == nil || attendant == "" {
+		return nil
+	}
+	if _, onDuty := p.shifts.ShiftAt(attendant, p.id, at); !onDuty {
+		return fmt.Errorf("%w: %s at %s on %s", ErrNotOnDuty, attendant, p.id, at.Format(time.DateTime))
+	}
+	return nil
+}
+
+// ShiftReport is what one attendant did during one shift
+type ShiftReport struct {
+	Shift        Shift
+	CarsParked   int
+	CarsReturned int     // Cars the attendant handed back to their drivers
+	Parked       []Visit // Cars parked during the shift, still parked ones with a zero ExitTime
+}
+
+// Report returns one report per scheduled shift overlapping from..to, in start order. Cars
+// that have left are read from the history store and cars still parked from the lots.
+func (s *ShiftSchedule) Report(history HistoryStore, lots []*ParkingLot, from, to time.Time) []ShiftReport {
+	var visits []Visit
+	if history != nil {
+		visits = history.Query(VisitFilter{})
+	}
+	for _, lot := range lots {
+		visits = append(visits, lot.currentVisits()...)
+	}
+
+	var reports []ShiftReport
+	for _, shift := range s.GetShifts() {
+		if !shift.Start.Before(to) || !from.Before(shift.End) {
+			continue
+		}
+		report := ShiftReport{Shift: shift}
+		for _, visit := range visits {
+			if visit.AttendantName == shift.Attendant && shift.covers(visit.LotID, visit.EntryTime) {
+				report.CarsParked++
+				report.Parked = append(report.Parked, visit)
+			}
+			if visit.ExitAttendantName == shift.Attendant && !visit.ExitTime.IsZero() && shift.covers(visit.LotID, visit.ExitTime) {
+				report.CarsReturned++
+			}
+		}
+		sort.Slice(report.Parked, func(i, j int) bool { return report.Parked[i].EntryTime.Before(report.Parked[j].EntryTime) })
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+// currentVisits returns the stays of the cars parked now, with a zero ExitTime
+func (p *ParkingLot) currentVisits() []Visit {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	visits := make([]Visit, 0, len(p.slotByPlate))
+	for _, slotID := range p.slotByPlate {
+		visits = append(visits, p.visitOf(p.slotInfo[slotID], time.Time{}, ""))
+	}
+	return visits
+}
@@ -0,0 +1,57 @@
+package domain
+
+// Slot represents a numbered parking space inside a lot.
+// Slot IDs are fixed when the lot is created and never shift when cars leave.
+type Slot struct {
+	ID          int      // Stable slot number within the lot
+	Row         string   // Row the slot belongs to, empty when the lot has no row layout
+	Number      int      // Position within its row, starting at 1; 0 when the lot has no row layout
+	Size        SlotSize // Cars the slot takes, see SlotSize.Fits
+	Accessible  bool     // Reserved for cars with handicap permits
+	Charging    bool     // Has a charger, kept for electric vehicles where possible
+	car         *Car     // Car currently occupying the slot, nil when free
+	overflow    bool     // Holds the back half of a large car parked from the previous slot
+	reservation string   // ID of the reservation holding the free slot, empty when not held
+}
+
+// newSlots creates the numbered slots for a lot of the given capacity
+func newSlots(capacity int) []*Slot {
+	if capacity < 0 {
+		capacity = 0
+	}
+	slots := make([]*Slot, capacity)
+	for i := range slots {
+		slots[i] = &Slot{ID: i}
+	}
+	return slots
+}
+
+// IsOccupied returns true if a car is parked in the slot
+func (s Slot) IsOccupied() bool {
+	return s.car != nil
+}
+
+// GetCar returns the car parked in the slot, if any
+func (s Slot) GetCar() (Car, bool) {
+	if s.car == nil {
+		return Car{}, false
+	}
+	return *s.car, true
+}
+
+// assign places a car in the slot
+func (s *Slot) assign(car Car) {
+	s.car = &car
+}
+
+// assignOverflow places the back half of a large car in the slot
+func (s *Slot) assignOverflow(car Car) {
+	s.car = &car
+	s.overflow = true
+}
+
+// release frees the slot
+func (s *Slot) release() {
+	s.car = nil
+	s.overflow = false
+}
@@ -0,0 +1,286 @@
+package domain
+
+import "fmt"
+
+// SlotSize is the size of car a slot is built for
+type SlotSize int
+
+const (
+	AnySize        SlotSize = iota // Unmarked slot that takes one car of any size
+	SmallSlot                      // Takes a small car
+	MediumSlot                     // Takes a small or medium car
+	LargeSlot                      // Takes a car of any size, or a van
+	BikeRack                       // Takes a bicycle
+	MotorcycleSlot                 // Takes a motorcycle or a bicycle
+	OversizeSlot                   // Takes any vehicle, and is the only slot a bus or truck fits
+
+	numSlotSizes = iota
+)
+
+// String returns string representation of SlotSize
+func (s SlotSize) String() string {
+	switch s {
+	case AnySize:
+		return "Any"
+	case SmallSlot:
+		return "Small"
+	case MediumSlot:
+		return "Medium"
+	case LargeSlot:
+		return "Large"
+	case BikeRack:
+		return "Bike rack"
+	case MotorcycleSlot:
+		return "Motorcycle"
+	case OversizeSlot:
+		return "Oversize"
+	default:
+		return "Unknown"
+	}
+}
+
+// Fits reports whether a car of the given size can park in the slot on its own. A large car
+// can also park across two adjacent small slots in the same row.
+func (s SlotSize) Fits(size CarSize) bool {
+	switch s {
+	case AnySize, LargeSlot, OversizeSlot:
+		return true
+	case MediumSlot:
+		return size == Small || size == Medium
+	case SmallSlot:
+		return size == Small
+	}
+	return false
+}
+
+// Accepts reports whether the vehicle can park in the slot on its own. Cars and vans follow
+// Fits, two-wheelers fit any slot but a bike rack only takes bicycles, and buses and trucks
+// need an oversize slot.
+func (s SlotSize) Accepts(vehicle Vehicle) bool {
+	return s.accepts(vehicle.Record())
+}
+
+func (s SlotSize) accepts(car Car) bool {
+	switch car.Type {
+	case VehicleBicycle:
+		return true
+	case VehicleMotorcycle:
+		return s != BikeRack
+	case VehicleBus, VehicleTruck:
+		return s == OversizeSlot
+	}
+	return s.Fits(car.Size)
+}
+
+// spansSmallSlots reports whether the car may park across two adjacent small slots
+func spansSmallSlots(car Car) bool {
+	return car.Size == Large && (car.Type == VehicleCar || car.Type == VehicleVan)
+}
+
+// slotSizes lists slot sizes in the order vehicles are placed in them, tightest fit first, so
+// large slots are kept for the vehicles that need them
+var slotSizes = []SlotSize{BikeRack, MotorcycleSlot, SmallSlot, MediumSlot, AnySize, LargeSlot, OversizeSlot}
+
+// SlotBlock is a run of consecutive slots of one size
+type SlotBlock struct {
+	Size  SlotSize
+	Slots int
+}
+
+// WithSlotSizes marks the lot's slots by size, filling them in slot order: the first block
+// gets slots 0..n-1 and so on. Slots past the last block stay AnySize, so a lot built without
+// sizes parks one car of any size per slot.
+func WithSlotSizes(blocks ...SlotBlock) LotOption {
+	return func(p *ParkingLot) {
+		next := 0
+		for _, block := range blocks {
+			for i := 0; i < block.Slots && next < len(p.slots); i++ {
+				p.slots[next].Size = block.Size
+				next++
+			}
+		}
+	}
+}
+
+// countSlotSizes sets up per-size capacity once the lot's options have laid out its slots
+func (p *ParkingLot) countSlotSizes() {
+	for _, slot := range p.slots {
+		p.slotsBySize[slot.Size]++
+		p.freeBySize[slot.Size]++
+		if slot.Accessible {
+			p.freeAccessible[slot.Size]++
+		}
+	}
+}
+
+// freeSlotFor returns the free slot that best fits the car headed to row, or nil if there is
+// none. Handicap cars get an accessible slot when one is free and other cars never do. Electric
+// vehicles get a charging slot when one fits and other vehicles only when nothing else does. A
+// large car or van that fits no single slot gets the first of two adjacent free small slots,
+// reported by spansTwo. Rows only narrow the search in lots with a row layout. The caller must hold p.mu
+// for writing.
+func (p *ParkingLot) freeSlotFor(row string, car Car, handicap bool) (slot *Slot, spansTwo bool, err error) {
+	first, last := 0, len(p.slots)-1
+	if len(p.rows) > 0 && row != "" {
+		found := false
+		for _, r := range p.rows {
+			if r.name == row {
+				first, last, found = r.first, r.last, true
+				break
+			}
+		}
+		if !found {
+			return nil, false, fmt.Errorf("%w: %s", ErrUnknownRow, row)
+		}
+	}
+	if p.firstFreeSlot() == nil {
+		return nil, false, nil
+	}
+	first = max(first, p.nextFree)
+
+	accessibility := []bool{false}
+	if handicap {
+		accessibility = []bool{true, false}
+	}
+	for _, accessible := range accessibility {
+		for _, charging := range p.chargingOrder(car) {
+			if slot := p.bestFreeSlot(first, last, car, accessible, charging); slot != nil {
+				return slot, false, nil
+			}
+		}
+	}
+	if spansSmallSlots(car) && p.freeBySize[SmallSlot] >= 2 {
+		for id := first; id < last; id++ {
+			if p.freeSmallPairAt(id) {
+				return p.slots[id], true, nil
+			}
+		}
+	}
+	return nil, false, nil
+}
+
+// chargingOrder returns whether to look at charging slots or the others first for the car.
+// Lots without charging slots are searched in a single pass. The caller must hold p.mu.
+func (p *ParkingLot) chargingOrder(car Car) []bool {
+	switch {
+	case p.chargingSlots == 0:
+		return []bool{false}
+	case car.IsElectric:
+		return []bool{true, false}
+	}
+	return []bool{false, true}
+}
+
+// bestFreeSlot returns the free slot with IDs first..last that most tightly fits the car,
+// looking only at accessible slots or only at the others, and likewise at charging slots or
+// the others. The caller must hold p.mu.
+func (p *ParkingLot) bestFreeSlot(first, last int, car Car, accessible, charging bool) *Slot {
+	for _, slotSize := range slotSizes {
+		free := p.freeBySize[slotSize] - p.freeAccessible[slotSize]
+		if accessible {
+			free = p.freeAccessible[slotSize]
+		}
+		if free == 0 || !slotSize.accepts(car) {
+			continue
+		}
+		for id := first; id <= last; id++ {
+			candidate := p.slots[id]
+			if candidate.Size == slotSize && candidate.Accessible == accessible && candidate.Charging == charging &&
+				!candidate.IsOccupied() && candidate.reservation == "" {
+				return candidate
+			}
+		}
+	}
+	return nil
+}
+
+// freeSmallPairAt reports whether slot id and the next one are free small slots in the same
+// row, neither of them accessible. The caller must hold p.mu.
+func (p *ParkingLot) freeSmallPairAt(id int) bool {
+	front, back := p.slots[id], p.slots[id+1]
+	return front.Size == SmallSlot && back.Size == SmallSlot &&
+		!front.Accessible && !back.Accessible &&
+		!front.IsOccupied() && !back.IsOccupied() && front.reservation == "" && back.reservation == "" &&
+		front.Row == back.Row
+}
+
+// slotsFree reports whether the slots a car would hold from info.SlotID exist, are free and
+// accept info.Car, e.g. before replaying a recorded park. A car spanning two slots needs two
+// small slots. The caller must hold p.mu.
+func (p *ParkingLot) slotsFree(info CarParkingInfo) bool {
+	if info.SlotID < 0 || info.SlotID+slotsHeld(info) > len(p.slots) {
+		return false
+	}
+	for id := info.SlotID; id < info.SlotID+slotsHeld(info); id++ {
+		if p.slots[id].IsOccupied() {
+			return false
+		}
+	}
+	if info.SpansTwoSlots {
+		return spansSmallSlots(info.Car) && p.slots[info.SlotID].Size == SmallSlot && p.slots[info.SlotID+1].Size == SmallSlot
+	}
+	return p.slots[info.SlotID].Size.accepts(info.Car)
+}
+
+// CanFit reports whether a car of the given size could park in the lot now
+func (p *ParkingLot) CanFit(size CarSize) bool {
+	return p.GetAvailableSpacesFor(size) > 0
+}
+
+// GetAvailableSpacesFor returns how many cars of the given size could park in the lot now,
+// counting every free slot they fit and, for large cars, pairs of adjacent free small slots.
+// Accessible slots are left out, see GetAvailableAccessibleSpaces.
+func (p *ParkingLot) GetAvailableSpacesFor(size CarSize) int {
+	return p.GetAvailableSpacesForVehicle(Car{Size: size})
+}
+
+// GetAvailableSpacesForVehicle returns how many vehicles like this one could park in the lot
+// now, counted like GetAvailableSpacesFor
+func (p *ParkingLot) GetAvailableSpacesForVehicle(vehicle Vehicle) int {
+	if vehicle == nil {
+		return 0
+	}
+	car := vehicle.Record()
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	free := p.freeAt(p.clock.Now())
+	spaces := 0
+	for _, slotSize := range slotSizes {
+		if slotSize.accepts(car) {
+			spaces += free[slotSize] - p.freeAccessible[slotSize]
+		}
+	}
+	if spansSmallSlots(car) && free[SmallSlot] >= 2 {
+		for id := 0; id < len(p.slots)-1; id++ {
+			if p.freeSmallPairAt(id) {
+				spaces++
+				id++ // The pair's back slot cannot start another pair
+			}
+		}
+	}
+	return spaces
+}
+
+// GetCapacityBySize returns the number of slots of each size in the lot
+func (p *ParkingLot) GetCapacityBySize() map[SlotSize]int {
+	return p.sizeCounts(p.slotsBySize) // Fixed at construction
+}
+
+// GetAvailableSpacesBySize returns the number of free slots of each size in the lot
+func (p *ParkingLot) GetAvailableSpacesBySize() map[SlotSize]int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.sizeCounts(p.freeAt(p.clock.Now()))
+}
+
+// sizeCounts returns counts by slot size for the sizes the lot has slots of
+func (p *ParkingLot) sizeCounts(counts [numSlotSizes]int) map[SlotSize]int {
+	bySize := make(map[SlotSize]int)
+	for size, slots := range p.slotsBySize {
+		if slots > 0 {
+			bySize[SlotSize(size)] = counts[size]
+		}
+	}
+	return bySize
+}
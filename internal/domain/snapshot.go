@@ -0,0 +1,249 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SnapshotVersion is the version of the snapshot format written by SaveSnapshot
+const SnapshotVersion = 1
+
+// Snapshot errors returned by Restore
+var (
+	ErrUnsupportedSnapshotVersion = errors.New("unsupported snapshot version")
+	ErrCorruptSnapshot            = errors.New("corrupt snapshot")
+)
+
+// LotSnapshot is the on-disk form of a parking lot's state
+type LotSnapshot struct {
+	Version      int              `json:"version"`
+	LotID        string           `json:"lot_id"`
+	LotName      string           `json:"lot_name,omitempty"`
+	Capacity     int              `json:"capacity"`
+	WasFull      bool             `json:"was_full"`
+	TakenAt      time.Time        `json:"taken_at"`
+	JournalSeq   uint64           `json:"journal_seq,omitempty"` // Last journal record reflected in the snapshot
+	Cars         []ParkedCarState `json:"cars"`
+	Tickets      []TicketState    `json:"tickets"`
+	Reservations []Reservation    `json:"reservations,omitempty"` // Booked reservations keep the slot they hold
+}
+
+// ParkedCarState is one occupied slot in a snapshot
+type ParkedCarState struct {
+	Car           Car              `json:"car"`
+	EnteredAs     *Car             `json:"entered_as,omitempty"` // Car as typed, when it differs from Car
+	SlotID        int              `json:"slot_id"`
+	Row           string           `json:"row,omitempty"`
+	IsHandicap    bool             `json:"is_handicap,omitempty"`
+	AttendantName string           `json:"attendant,omitempty"`
+	TicketID      string           `json:"ticket_id,omitempty"`
+	ParkedAt      time.Time        `json:"parked_at"`
+	PlateFlag     string           `json:"plate_flag,omitempty"`
+	SpansTwoSlots bool             `json:"spans_two_slots,omitempty"`
+	PermitFlag    string           `json:"permit_flag,omitempty"`
+	Charging      *ChargingSession `json:"charging,omitempty"` // Vehicle's charging session, if any
+}
+
+// TicketState is one issued ticket in a snapshot
+type TicketState struct {
+	Ticket Ticket `json:"ticket"`
+	Used   bool   `json:"used"`
+}
+
+// Snapshot captures the lot's current state
+func (p *ParkingLot) Snapshot() LotSnapshot {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	snapshot := LotSnapshot{
+		Version:  SnapshotVersion,
+		LotID:    p.id,
+		LotName:  p.name,
+		Capacity: p.capacity,
+		WasFull:  p.wasFull,
+		TakenAt:  p.clock.Now(),
+		Cars:     make([]ParkedCarState, 0, p.occupied),
+		Tickets:  make([]TicketState, 0, len(p.tickets)),
+	}
+	if p.journal != nil {
+		snapshot.JournalSeq = p.journal.LastSeq()
+	}
+
+	for _, slot := range p.slots {
+		car, ok := slot.GetCar()
+		if !ok || slot.overflow {
+			continue
+		}
+		info := p.slotInfo[slot.ID]
+		snapshot.Cars = append(snapshot.Cars, ParkedCarState{
+			Car:           car,
+			EnteredAs:     enteredAs(info),
+			SlotID:        slot.ID,
+			Row:           info.Row,
+			IsHandicap:    info.IsHandicap,
+			AttendantName: info.AttendantName,
+			TicketID:      info.TicketID,
+			ParkedAt:      info.ParkedAt,
+			PlateFlag:     info.PlateFlag,
+			SpansTwoSlots: info.SpansTwoSlots,
+			PermitFlag:    info.PermitFlag,
+			Charging:      p.chargingState(car.Plate),
+		})
+	}
+
+	for _, issued := range p.tickets {
+		snapshot.Tickets = append(snapshot.Tickets, TicketState{Ticket: issued.ticket, Used: issued.used})
+	}
+
+	for _, reservation := range p.reservations {
+		snapshot.Reservations = append(snapshot.Reservations, *reservation)
+	}
+
+	return snapshot
+}
+
+// SaveSnapshot writes the lot's state to w as versioned JSON
+func (p *ParkingLot) SaveSnapshot(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(p.Snapshot())
+}
+
+// SaveSnapshotFile writes the lot's state to path. The file is replaced atomically so a
+// crash mid-write leaves the previous snapshot intact.
+func (p *ParkingLot) SaveSnapshotFile(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := p.SaveSnapshot(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Restore rebuilds a lot from a snapshot written by SaveSnapshot. Observers, tariffs, clocks and
+// registries are not part of a snapshot; pass them as options to re-register them on the restored lot.
+func Restore(r io.Reader, opts ...LotOption) (*ParkingLot, error) {
+	var snapshot LotSnapshot
+	if err := json.NewDecoder(r).Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCorruptSnapshot, err)
+	}
+	return RestoreSnapshot(snapshot, opts...)
+}
+
+// RestoreFile rebuilds a lot from a snapshot file written by SaveSnapshotFile
+func RestoreFile(path string, opts ...LotOption) (*ParkingLot, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Restore(file, opts...)
+}
+
+// RestoreSnapshot rebuilds a lot from an in-memory snapshot. Slot sizes come from the options,
+// as for NewParkingLot; a car whose recorded slot does not accept it, or a reservation holding
+// a slot it could not hold, makes the snapshot corrupt. Charging sessions the snapshot does not
+// know of are stopped, see WithCharger.
+func RestoreSnapshot(snapshot LotSnapshot, opts ...LotOption) (*ParkingLot, error) {
+	lot, err := restoreSnapshot(snapshot, opts...)
+	if err != nil {
+		return nil, err
+	}
+	lot.stopOrphanedCharging()
+	return lot, nil
+}
+
+// restoreSnapshot rebuilds a lot from a snapshot, leaving the charger as it is so RecoverLot
+// can replay sessions started after the snapshot was taken
+func restoreSnapshot(snapshot LotSnapshot, opts ...LotOption) (*ParkingLot, error) {
+	if snapshot.Version != SnapshotVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedSnapshotVersion, snapshot.Version)
+	}
+	if snapshot.Capacity < 0 || len(snapshot.Cars) > snapshot.Capacity {
+		return nil, fmt.Errorf("%w: %d cars in a lot of capacity %d", ErrCorruptSnapshot, len(snapshot.Cars), snapshot.Capacity)
+	}
+
+	lot := NewParkingLot(snapshot.Capacity, opts...)
+	if snapshot.LotID != "" {
+		lot.id = snapshot.LotID
+	}
+	if snapshot.LotName != "" {
+		lot.name = snapshot.LotName
+	}
+
+	for _, state := range snapshot.Tickets {
+		lot.tickets[state.Ticket.ID] = &issuedTicket{ticket: state.Ticket, used: state.Used}
+	}
+
+	for _, state := range snapshot.Cars {
+		car := NormalizeCar(state.Car)
+		entered := state.Car
+		if state.EnteredAs != nil {
+			entered = *state.EnteredAs
+		}
+		info := CarParkingInfo{
+			Car:           car,
+			EnteredAs:     entered,
+			Row:           state.Row,
+			SlotID:        state.SlotID,
+			IsHandicap:    state.IsHandicap,
+			AttendantName: state.AttendantName,
+			TicketID:      state.TicketID,
+			PlateFlag:     state.PlateFlag,
+			SpansTwoSlots: state.SpansTwoSlots,
+			PermitFlag:    state.PermitFlag,
+		}
+		if !lot.slotsFree(info) {
+			return nil, fmt.Errorf("%w: invalid slot %d for %s", ErrCorruptSnapshot, state.SlotID, state.Car.Plate)
+		}
+		if _, exists := lot.slotByPlate[car.Plate]; exists {
+			return nil, fmt.Errorf("%w: plate %s parked twice", ErrCorruptSnapshot, state.Car.Plate)
+		}
+		if state.Charging != nil {
+			if state.Charging.Plate != car.Plate || state.Charging.SlotID != state.SlotID {
+				return nil, fmt.Errorf("%w: charging session of %s is for another car", ErrCorruptSnapshot, state.Car.Plate)
+			}
+			lot.adoptCharging(*state.Charging, snapshot.TakenAt)
+		}
+		lot.occupy(lot.slots[state.SlotID], info, state.ParkedAt)
+	}
+
+	for _, state := range snapshot.Reservations {
+		if state.ID == "" || lot.reservationByID(state.ID) != nil {
+			return nil, fmt.Errorf("%w: invalid reservation %q", ErrCorruptSnapshot, state.ID)
+		}
+		reservation := state
+		if reservation.Status == ReservationBooked && reservation.SlotID != -1 {
+			if !lot.canHold(reservation.SlotID, reservation.SlotSize) {
+				return nil, fmt.Errorf("%w: reservation %s cannot hold slot %d", ErrCorruptSnapshot, state.ID, state.SlotID)
+			}
+			lot.hold(&reservation, lot.slots[reservation.SlotID])
+		}
+		lot.reservations = append(lot.reservations, &reservation)
+	}
+	lot.wasFull = snapshot.WasFull
+
+	// A registry passed as an option learns about the restored cars
+	if lot.registry != nil {
+		lot.registry.Register(lot)
+	}
+
+	return lot, nil
+}
@@ -0,0 +1,245 @@
+package domain
+
+// ParkingRequest describes a vehicle arriving to be parked
+type ParkingRequest struct {
+	Vehicle  Vehicle
+	Handicap bool // The driver asks for an accessible slot
+}
+
+// ParkingStrategy chooses which of the lots a vehicle is parked in. Lots are given nearest
+// first. ChooseLot returns nil if no lot will do; if the chosen lot fills up before the
+// vehicle is parked the attendant asks again without it.
+type ParkingStrategy interface {
+	ChooseLot(lots []*ParkingLot, req ParkingRequest) *ParkingLot
+}
+
+// StrategyFunc adapts a function to ParkingStrategy
+type StrategyFunc func(lots []*ParkingLot, req ParkingRequest) *ParkingLot
+
+// ChooseLot implements ParkingStrategy
+func (f StrategyFunc) ChooseLot(lots []*ParkingLot, req ParkingRequest) *ParkingLot {
+	return f(lots, req)
+}
+
+// NearestFirstStrategy parks in the nearest lot with room for the vehicle
+type NearestFirstStrategy struct{}
+
+// ChooseLot implements ParkingStrategy
+func (NearestFirstStrategy) ChooseLot(lots []*ParkingLot, req ParkingRequest) *ParkingLot {
+	for _, lot := range lots {
+		if lot.CanFitVehicle(req.Vehicle) {
+			return lot
+		}
+	}
+	return nil
+}
+
+// EvenStrategy parks in the lot with the fewest parked vehicles, spreading them evenly
+type EvenStrategy struct{}
+
+// ChooseLot implements ParkingStrategy
+func (EvenStrategy) ChooseLot(lots []*ParkingLot, req ParkingRequest) *ParkingLot {
+	var selected *ParkingLot
+	minCars := -1
+	for _, lot := range lots {
+		if !lot.CanFitVehicle(req.Vehicle) {
+			continue
+		}
+		if parked := lot.GetParkedCarsCount(); minCars == -1 || parked < minCars {
+			minCars = parked
+			selected = lot
+		}
+	}
+	return selected
+}
+
+// MostSpaceStrategy parks in the lot with room for the most vehicles like this one
+type MostSpaceStrategy struct{}
+
+// ChooseLot implements ParkingStrategy
+func (MostSpaceStrategy) ChooseLot(lots []*ParkingLot, req ParkingRequest) *ParkingLot {
+	var selected *ParkingLot
+	maxSpaces := 0
+	for _, lot := range lots {
+		if spaces := lot.GetAvailableSpacesForVehicle(req.Vehicle); spaces > maxSpaces {
+			maxSpaces = spaces
+			selected = lot
+		}
+	}
+	return selected
+}
+
+// FillFirstStrategy parks in the fullest lot with room for the vehicle, so one lot fills up
+// before the next is used and the others can be closed off at quiet times
+type FillFirstStrategy struct{}
+
+// ChooseLot implements ParkingStrategy
+func (FillFirstStrategy) ChooseLot(lots []*ParkingLot, req ParkingRequest) *ParkingLot {
+	var selected *ParkingLot
+	maxCars := -1
+	for _, lot := range lots {
+		if !lot.CanFitVehicle(req.Vehicle) {
+			continue
+		}
+		if parked := lot.GetParkedCarsCount(); parked > maxCars {
+			maxCars = parked
+			selected = lot
+		}
+	}
+	return selected
+}
+
+// BestFitStrategy parks in the nearest lot whose free slots fit the vehicle most tightly,
+// keeping bigger slots elsewhere free for the vehicles that need them
+type BestFitStrategy struct{}
+
+// ChooseLot implements ParkingStrategy
+func (BestFitStrategy) ChooseLot(lots []*ParkingLot, req ParkingRequest) *ParkingLot {
+	if req.Vehicle == nil {
+		return nil
+	}
+	var selected *ParkingLot
+	bestRank := -1
+	for _, lot := range lots {
+		rank, fits := lot.fitRank(req.Vehicle.Record())
+		if fits && (bestRank == -1 || rank < bestRank) {
+			bestRank = rank
+			selected = lot
+		}
+	}
+	return selected
+}
+
+// AccessibleFirstStrategy parks handicap drivers in the nearest lot with a free accessible
+// slot, and everyone else like Fallback
+type AccessibleFirstStrategy struct {
+	Fallback ParkingStrategy // NearestFirstStrategy when nil
+}
+
+// ChooseLot implements ParkingStrategy
+func (s AccessibleFirstStrategy) ChooseLot(lots []*ParkingLot, req ParkingRequest) *ParkingLot {
+	if req.Handicap {
+		for _, lot := range lots {
+			if lot.GetAvailableAccessibleSpaces() > 0 {
+				return lot
+			}
+		}
+	}
+	return orNearest(s.Fallback).ChooseLot(lots, req)
+}
+
+// ChargingFirstStrategy parks electric vehicles in the nearest lot where they can charge,
+// and other vehicles like Fallback
+type ChargingFirstStrategy struct {
+	Fallback ParkingStrategy // NearestFirstStrategy when nil
+}
+
+// ChooseLot implements ParkingStrategy
+func (s ChargingFirstStrategy) ChooseLot(lots []*ParkingLot, req ParkingRequest) *ParkingLot {
+	if IsElectric(req) {
+		for _, lot := range lots {
+			if lot.CanCharge(req.Vehicle) {
+				return lot
+			}
+		}
+	}
+	return orNearest(s.Fallback).ChooseLot(lots, req)
+}
+
+// StrategyRule sends the requests it matches to a strategy
+type StrategyRule struct {
+	Matches  func(req ParkingRequest) bool
+	Strategy ParkingStrategy
+}
+
+// DispatchStrategy picks a strategy for each request from its rules, the first match winning,
+// and uses Default for requests no rule matches
+type DispatchStrategy struct {
+	Rules   []StrategyRule
+	Default ParkingStrategy // NearestFirstStrategy when nil
+}
+
+// NewDispatchStrategy creates a dispatcher trying the rules in order
+func NewDispatchStrategy(fallback ParkingStrategy, rules ...StrategyRule) *DispatchStrategy {
+	return &DispatchStrategy{Rules: rules, Default: fallback}
+}
+
+// ChooseLot implements ParkingStrategy
+func (d *DispatchStrategy) ChooseLot(lots []*ParkingLot, req ParkingRequest) *ParkingLot {
+	for _, rule := range d.Rules {
+		if rule.Matches(req) {
+			return rule.Strategy.ChooseLot(lots, req)
+		}
+	}
+	return orNearest(d.Default).ChooseLot(lots, req)
+}
+
+// IsHandicap matches requests for an accessible slot
+func IsHandicap(req ParkingRequest) bool {
+	return req.Handicap
+}
+
+// IsElectric matches electric vehicles
+func IsElectric(req ParkingRequest) bool {
+	return req.Vehicle != nil && req.Vehicle.Record().IsElectric
+}
+
+// IsLarge matches large cars and every vehicle that needs a large slot
+func IsLarge(req ParkingRequest) bool {
+	return req.Vehicle != nil && req.Vehicle.Record().Size == Large
+}
+
+// IsVehicleType matches vehicles of the given types
+func IsVehicleType(types ...VehicleType) func(req ParkingRequest) bool {
+	return func(req ParkingRequest) bool {
+		if req.Vehicle == nil {
+			return false
+		}
+		for _, vehicleType := range types {
+			if req.Vehicle.GetType() == vehicleType {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// DefaultStrategy is the strategy attendants use unless given another: accessible slots for
+// handicap drivers, chargers for electric vehicles, the lot with the most room for large
+// vehicles, and otherwise the nearest lot
+func DefaultStrategy() *DispatchStrategy {
+	return NewDispatchStrategy(NearestFirstStrategy{},
+		StrategyRule{Matches: IsHandicap, Strategy: AccessibleFirstStrategy{}},
+		StrategyRule{Matches: IsElectric, Strategy: ChargingFirstStrategy{}},
+		StrategyRule{Matches: IsLarge, Strategy: MostSpaceStrategy{}},
+	)
+}
+
+// orNearest returns the strategy, or NearestFirstStrategy if it is nil
+func orNearest(strategy ParkingStrategy) ParkingStrategy {
+	if strategy == nil {
+		return NearestFirstStrategy{}
+	}
+	return strategy
+}
+
+// fitRank returns how tightly the lot's free slots fit the car, lower being tighter, and
+// false if no free slot fits it. Accessible slots are left out as for GetAvailableSpacesFor.
+func (p *ParkingLot) fitRank(car Car) (int, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	free := p.freeAt(p.clock.Now())
+	for rank, slotSize := range slotSizes {
+		if slotSize.accepts(car) && free[slotSize] > p.freeAccessible[slotSize] {
+			return rank, true
+		}
+	}
+	if spansSmallSlots(car) && free[SmallSlot] >= 2 {
+		for id := 0; id < len(p.slots)-1; id++ {
+			if p.freeSmallPairAt(id) {
+				return len(slotSizes), true
+			}
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,218 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Money is an amount in the smallest currency unit, e.g. paise or cents
+type Money int64
+
+// String formats the amount with two decimal places
+func (m Money) String() string {
+	sign := ""
+	if m < 0 {
+		sign = "-"
+		m = -m
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, m/100, m%100)
+}
+
+// Stay describes one completed visit to be charged
+type Stay struct {
+	Plate     string
+	Size      CarSize
+	Type      VehicleType
+	EnergyKWh float64 // Energy delivered by the lot's chargers during the stay
+	EntryTime time.Time
+	ExitTime  time.Time
+}
+
+// Duration returns how long the car stayed
+func (s Stay) Duration() time.Duration {
+	return s.ExitTime.Sub(s.EntryTime)
+}
+
+// LineItem is one charge on a receipt
+type LineItem struct {
+	Description string
+	Amount      Money
+}
+
+// Receipt is the itemised bill handed over when a car leaves
+type Receipt struct {
+	TicketID  string
+	LotID     string
+	SlotID    int
+	Plate     string
+	Size      CarSize
+	Type      VehicleType
+	EnergyKWh float64
+	EntryTime time.Time
+	ExitTime  time.Time
+	Duration  time.Duration
+	Items     []LineItem
+	Total     Money
+}
+
+// addItem appends a charge and keeps the total in step
+func (r *Receipt) addItem(description string, amount Money) {
+	r.Items = append(r.Items, LineItem{Description: description, Amount: amount})
+	r.Total += amount
+}
+
+// Tariff prices a stay. Implementations return the line items; the lot fills in the rest of the receipt.
+type Tariff interface {
+	Charge(stay Stay) []LineItem
+}
+
+// OvernightRate charges a flat amount for each night window a stay overlaps,
+// instead of hourly charges for the time inside the window
+type OvernightRate struct {
+	StartHour int   // Hour the window opens, e.g. 22
+	EndHour   int   // Hour the window closes the next morning, e.g. 6
+	Flat      Money // Charge per night
+}
+
+// StandardTariff is the configurable tariff used by most lots: hourly rates per car size,
+// an initial free period, a daily cap per 24 hours and an optional overnight flat rate.
+// Vehicles other than cars can be priced by type, which takes precedence over their size.
+// Energy delivered by chargers is billed per kWh on top of the parking time.
+// Hourly time is billed per started hour within each 24-hour period counted from entry.
+type StandardTariff struct {
+	HourlyRates      map[CarSize]Money
+	FreeMinutes      int
+	DailyCaps        map[CarSize]Money // No cap for sizes without an entry
+	Overnight        *OvernightRate
+	VehicleRates     map[VehicleType]Money // Hourly rates by vehicle type, e.g. for buses
+	VehicleDailyCaps map[VehicleType]Money // Daily caps by vehicle type
+	EnergyRate       Money                 // Price per kWh charged
+}
+
+// ratesFor returns the hourly rate and daily cap that apply to the stay, and how to describe it
+func (t StandardTariff) ratesFor(stay Stay) (rate Money, dailyCap Money, hasCap bool, label string) {
+	rate = t.HourlyRates[stay.Size]
+	dailyCap, hasCap = t.DailyCaps[stay.Size]
+	label = stay.Size.String()
+	if stay.Type == VehicleCar {
+		return rate, dailyCap, hasCap, label
+	}
+	if typeRate, priced := t.VehicleRates[stay.Type]; priced {
+		rate, label = typeRate, stay.Type.String()
+	}
+	if typeCap, capped := t.VehicleDailyCaps[stay.Type]; capped {
+		dailyCap, hasCap = typeCap, true
+	}
+	return rate, dailyCap, hasCap, label
+}
+
+// Charge implements Tariff
+func (t StandardTariff) Charge(stay Stay) []LineItem {
+	items := t.chargeTime(stay)
+	if stay.EnergyKWh > 0 {
+		amount := Money(math.Round(stay.EnergyKWh * float64(t.EnergyRate)))
+		items = append(items, LineItem{Description: fmt.Sprintf("Charging: %.2f kWh at %s/kWh", stay.EnergyKWh, t.EnergyRate), Amount: amount})
+	}
+	return items
+}
+
+// chargeTime prices the time parked
+func (t StandardTariff) chargeTime(stay Stay) []LineItem {
+	var receipt Receipt
+	duration := stay.Duration()
+	if duration <= 0 {
+		return nil
+	}
+
+	free := time.Duration(t.FreeMinutes) * time.Minute
+	if free > 0 {
+		receipt.addItem(fmt.Sprintf("First %d minutes free", t.FreeMinutes), 0)
+		if duration <= free {
+			return receipt.Items
+		}
+	}
+	billedFrom := stay.EntryTime.Add(free)
+
+	// Time inside overnight windows is covered by the flat rate
+	var nights []interval
+	if t.Overnight != nil {
+		nights = t.Overnight.windows(billedFrom, stay.ExitTime)
+		if len(nights) > 0 {
+			receipt.addItem(fmt.Sprintf("Overnight flat rate x%d", len(nights)), Money(len(nights))*t.Overnight.Flat)
+		}
+	}
+
+	rate, dailyCap, hasCap, label := t.ratesFor(stay)
+	day := 0
+	for dayStart := stay.EntryTime; dayStart.Before(stay.ExitTime); dayStart = dayStart.Add(24 * time.Hour) {
+		day++
+		period := interval{start: dayStart, end: dayStart.Add(24 * time.Hour)}.clip(billedFrom, stay.ExitTime)
+		billed := period.durationOutside(nights)
+		if billed <= 0 {
+			continue
+		}
+
+		hours := int((billed + time.Hour - 1) / time.Hour)
+		amount := Money(hours) * rate
+		receipt.addItem(fmt.Sprintf("Day %d: %dh at %s/h (%s)", day, hours, rate, label), amount)
+		if hasCap && amount > dailyCap {
+			receipt.addItem(fmt.Sprintf("Day %d: daily cap %s", day, dailyCap), dailyCap-amount)
+		}
+	}
+
+	return receipt.Items
+}
+
+// interval is a half-open time range [start, end)
+type interval struct {
+	start time.Time
+	end   time.Time
+}
+
+// clip narrows the interval to [from, to)
+func (i interval) clip(from, to time.Time) interval {
+	if i.start.Before(from) {
+		i.start = from
+	}
+	if i.end.After(to) {
+		i.end = to
+	}
+	return i
+}
+
+// length returns the interval's duration, or zero if it is empty
+func (i interval) length() time.Duration {
+	if !i.end.After(i.start) {
+		return 0
+	}
+	return i.end.Sub(i.start)
+}
+
+// durationOutside returns the part of the interval not covered by any of the excluded intervals,
+// which must not overlap each other
+func (i interval) durationOutside(excluded []interval) time.Duration {
+	remaining := i.length()
+	for _, ex := range excluded {
+		remaining -= ex.clip(i.start, i.end).length()
+	}
+	return remaining
+}
+
+// windows returns the night windows overlapping [from, to), each clipped to the stay
+func (o OvernightRate) windows(from, to time.Time) []interval {
+	var nights []interval
+	day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location()).AddDate(0, 0, -1)
+	for !day.After(to) {
+		start := day.Add(time.Duration(o.StartHour) * time.Hour)
+		end := day.Add(time.Duration(o.EndHour) * time.Hour)
+		if o.EndHour <= o.StartHour {
+			end = end.AddDate(0, 0, 1)
+		}
+		if night := (interval{start: start, end: end}).clip(from, to); night.length() > 0 {
+			nights = append(nights, night)
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return nights
+}
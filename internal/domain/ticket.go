@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// Ticket is handed to the driver when a car is parked and must be presented to get it back
+type Ticket struct {
+	ID            string // Random, unguessable ticket number
+	LotID         string
+	SlotID        int
+	Plate         string
+	EntryTime     time.Time
+	AttendantName string // Empty when the car was parked without an attendant
+}
+
+// issuedTicket is the lot's own record of a ticket, used to spot forged or replayed tickets
+type issuedTicket struct {
+	ticket Ticket
+	used   bool
+}
+
+// newTicketID returns a random ticket number
+func newTicketID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		panic("domain: cannot generate ticket ID: " + err.Error())
+	}
+	return hex.EncodeToString(buf)
+}
+
+// matches reports whether a presented ticket carries exactly the details the lot issued
+func (t Ticket) matches(issued Ticket) bool {
+	return t.ID == issued.ID &&
+		t.LotID == issued.LotID &&
+		t.SlotID == issued.SlotID &&
+		t.Plate == issued.Plate &&
+		t.EntryTime.Equal(issued.EntryTime) &&
+		t.AttendantName == issued.AttendantName
+}
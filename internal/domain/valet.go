@@ -0,0 +1,372 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Valet errors
+var (
+	ErrValetOnly           = errors.New("lot is valet-only")
+	ErrCabinetFull         = errors.New("key cabinet has no free hook")
+	ErrUnknownValetTicket  = errors.New("valet ticket was not issued by this service")
+	ErrRetrievalRequested  = errors.New("retrieval already requested")
+	ErrNoRetrievalsWaiting = errors.New("no retrievals waiting")
+	ErrNoValetAttendants   = errors.New("no valet attendant can park the vehicle")
+)
+
+// WithValetOnly turns drivers away: only attendants may park cars in the lot or hand them back
+func WithValetOnly() LotOption {
+	return func(p *ParkingLot) {
+		p.valetOnly = true
+	}
+}
+
+// checkValet returns ErrValetOnly if a valet-only lot is asked to act without an attendant.
+// The caller must hold p.mu.
+func (p *ParkingLot) checkValet(attendant string) error {
+	if p.valetOnly && attendant == "" {
+		return fmt.Errorf("%w: %s", ErrValetOnly, p.id)
+	}
+	return nil
+}
+
+// KeyCabinet holds car keys on numbered hooks, starting at 1. It is safe for concurrent use.
+type KeyCabinet struct {
+	mu    sync.Mutex
+	hooks []string // Plate whose keys hang on each hook, empty when free; index 0 is hook 1
+}
+
+// NewKeyCabinet creates a cabinet with the given number of hooks
+func NewKeyCabinet(hooks int) *KeyCabinet {
+	return &KeyCabinet{hooks: make([]string, max(hooks, 0))}
+}
+
+// hang puts a car's keys on the lowest free hook and returns its number
+func (c *KeyCabinet) hang(plate string) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, held := range c.hooks {
+		if held == "" {
+			c.hooks[i] = plate
+			return i + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: cannot take the keys of %s", ErrCabinetFull, plate)
+}
+
+// take removes the keys from a hook
+func (c *KeyCabinet) take(hook int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if hook >= 1 && hook <= len(c.hooks) {
+		c.hooks[hook-1] = ""
+	}
+}
+
+// HookOf returns the hook holding the keys of the plate
+func (c *KeyCabinet) HookOf(plate string) (int, bool) {
+	plate = NormalizePlate(plate)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, held := range c.hooks {
+		if held == plate {
+			return i + 1, true
+		}
+	}
+	return 0, false
+}
+
+// GetFreeHooks returns the number of empty hooks
+func (c *KeyCabinet) GetFreeHooks() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	free := 0
+	for _, held := range c.hooks {
+		if held == "" {
+			free++
+		}
+	}
+	return free
+}
+
+// ValetStatus is where a valet customer's car is in the workflow
+type ValetStatus int
+
+const (
+	ValetParked    ValetStatus = iota // Parked, keys in the cabinet
+	ValetRequested                    // The customer has asked for the car back
+	ValetReturned                     // The car and keys are back with the customer
+)
+
+// String returns string representation of ValetStatus
+func (s ValetStatus) String() string {
+	switch s {
+	case ValetParked:
+		return "Parked"
+	case ValetRequested:
+		return "Requested"
+	case ValetReturned:
+		return "Returned"
+	default:
+		return "Unknown"
+	}
+}
+
+// ValetTicket is handed to a customer dropping a car off with the valet
+type ValetTicket struct {
+	ID        string // Random, unguessable ticket number
+	Customer  string
+	Plate     string
+	LotID     string
+	SlotID    int
+	Hook      int    // Key cabinet hook holding the keys
+	ParkedBy  string // Attendant who parked the car
+	DroppedAt time.Time
+	Status    ValetStatus
+}
+
+// KeyHandoff records keys changing hands, so disputes over a car can be settled
+type KeyHandoff struct {
+	TicketID string
+	Plate    string
+	From     string // Customer, attendant, or "Cabinet hook <n>"
+	To       string
+	At       time.Time
+}
+
+// RetrievalRequest is a customer waiting for their car
+type RetrievalRequest struct {
+	TicketID      string
+	Plate         string
+	RequestedAt   time.Time
+	Position      int           // 1 for the next car to be fetched
+	EstimatedWait time.Duration // Until the car is expected at the desk
+}
+
+// valetCar is the service's record of a dropped-off car
+type valetCar struct {
+	ticket    ValetTicket
+	lot       *ParkingLot
+	lotTicket Ticket
+}
+
+// ValetService runs valet parking for a set of lots: attendants park dropped-off cars, keys
+// are kept in a numbered cabinet, and customers queue for their cars. Every key handoff is
+// recorded. It is safe for concurrent use.
+type ValetService struct {
+	mu            sync.Mutex
+	lots          []*ParkingLot
+	attendants    []*ParkingAttendant
+	nextAttendant int // Round-robin position for dispatching
+	cabinet       *KeyCabinet
+	clock         Clock
+	retrievalTime time.Duration        // Time one attendant takes to fetch a car
+	cars          map[string]*valetCar // By valet ticket ID
+	queue         []RetrievalRequest   // Customers waiting for their cars, in order
+	handoffs      []KeyHandoff
+}
+
+// ValetOption configures optional parts of a valet service
+type ValetOption func(*ValetService)
+
+// WithKeyCabinet keeps keys in the given cabinet instead of one with a hook per slot
+func WithKeyCabinet(cabinet *KeyCabinet) ValetOption {
+	return func(s *ValetService) {
+		s.cabinet = cabinet
+	}
+}
+
+// WithValetClock makes the service read time from the given clock instead of the system clock
+func WithValetClock(clock Clock) ValetOption {
+	return func(s *ValetService) {
+		s.clock = clock
+	}
+}
+
+// WithRetrievalTime sets how long one attendant takes to fetch a car, used to estimate waits.
+// The default is five minutes.
+func WithRetrievalTime(d time.Duration) ValetOption {
+	return func(s *ValetService) {
+		s.retrievalTime = d
+	}
+}
+
+// NewValetService creates a valet service parking in the given lots, nearest first
+func NewValetService(lots []*ParkingLot, attendants []*ParkingAttendant, opts ...ValetOption) *ValetService {
+	s := &ValetService{
+		lots:          append([]*ParkingLot(nil), lots...),
+		attendants:    append([]*ParkingAttendant(nil), attendants...),
+		clock:         RealClock{},
+		retrievalTime: 5 * time.Minute,
+		cars:          make(map[string]*valetCar),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.cabinet == nil {
+		hooks := 0
+		for _, lot := range lots {
+			hooks += len(lot.GetSlots())
+		}
+		s.cabinet = NewKeyCabinet(hooks)
+	}
+	return s
+}
+
+// DropOff takes a customer's car and keys and dispatches an attendant to park it. The keys
+// are hung on a hook first, so a full cabinet turns the car away before it is parked.
+// Attendants are dispatched in turn, skipping any who are off duty. The returned ticket says
+// where the car was parked and which hook holds its keys.
+func (s *ValetService) DropOff(customer string, vehicle Vehicle) (ValetTicket, error) {
+	if vehicle == nil {
+		return ValetTicket{}, fmt.Errorf("%w: no vehicle given", ErrInvalidCar)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	ticket := ValetTicket{ID: newTicketID(), Customer: customer, Plate: NormalizePlate(vehicle.GetPlate()), DroppedAt: now}
+	hook, err := s.cabinet.hang(ticket.Plate)
+	if err != nil {
+		return ValetTicket{}, err
+	}
+	for i := range s.attendants {
+		attendant := s.attendants[(s.nextAttendant+i)%len(s.attendants)]
+		lotTicket, err := attendant.Park(s.lots, ParkingRequest{Vehicle: vehicle})
+		if errors.Is(err, ErrNotOnDuty) {
+			continue
+		}
+		if err != nil {
+			s.cabinet.take(hook)
+			return ValetTicket{}, err
+		}
+		s.nextAttendant = (s.nextAttendant + i + 1) % len(s.attendants)
+
+		ticket.LotID, ticket.SlotID, ticket.Hook, ticket.ParkedBy = lotTicket.LotID, lotTicket.SlotID, hook, attendant.GetName()
+		s.cars[ticket.ID] = &valetCar{ticket: ticket, lot: s.lotByID(lotTicket.LotID), lotTicket: lotTicket}
+		s.recordHandoff(ticket, customer, attendant.GetName(), now)
+		s.recordHandoff(ticket, attendant.GetName(), hookName(hook), s.clock.Now())
+		return ticket, nil
+	}
+	s.cabinet.take(hook)
+	return ValetTicket{}, fmt.Errorf("%w: %s", ErrNoValetAttendants, ticket.Plate)
+}
+
+// RequestRetrieval queues the customer for their car and estimates the wait
+func (s *ValetService) RequestRetrieval(ticketID string) (RetrievalRequest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	car, exists := s.cars[ticketID]
+	switch {
+	case !exists:
+		return RetrievalRequest{}, fmt.Errorf("%w: %s", ErrUnknownValetTicket, ticketID)
+	case car.ticket.Status != ValetParked:
+		return RetrievalRequest{}, fmt.Errorf("%w: %s is %s", ErrRetrievalRequested, ticketID, car.ticket.Status)
+	}
+	car.ticket.Status = ValetRequested
+	s.queue = append(s.queue, RetrievalRequest{TicketID: ticketID, Plate: car.ticket.Plate, RequestedAt: s.clock.Now()})
+	return s.retrievalRequest(len(s.queue) - 1), nil
+}
+
+// GetRetrievalQueue returns the customers waiting for their cars, next first
+func (s *ValetService) GetRetrievalQueue() []RetrievalRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	requests := make([]RetrievalRequest, len(s.queue))
+	for i := range s.queue {
+		requests[i] = s.retrievalRequest(i)
+	}
+	return requests
+}
+
+// RetrieveNext sends the attendant for the car at the front of the queue: the attendant takes
+// the keys from the cabinet, checks the car out and hands car and keys to the customer
+func (s *ValetService) RetrieveNext(attendant *ParkingAttendant) (ValetTicket, Receipt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.queue) == 0 {
+		return ValetTicket{}, Receipt{}, ErrNoRetrievalsWaiting
+	}
+	car := s.cars[s.queue[0].TicketID]
+	receipt, err := attendant.CheckOutCar(car.lot, car.lotTicket)
+	if err != nil {
+		return ValetTicket{}, Receipt{}, err
+	}
+	s.queue = s.queue[1:]
+
+	now := s.clock.Now()
+	s.cabinet.take(car.ticket.Hook)
+	s.recordHandoff(car.ticket, hookName(car.ticket.Hook), attendant.GetName(), now)
+	s.recordHandoff(car.ticket, attendant.GetName(), car.ticket.Customer, now)
+	car.ticket.Status = ValetReturned
+	return car.ticket, receipt, nil
+}
+
+// GetTicket returns the current state of a valet ticket
+func (s *ValetService) GetTicket(ticketID string) (ValetTicket, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	car, exists := s.cars[ticketID]
+	if !exists {
+		return ValetTicket{}, false
+	}
+	return car.ticket, true
+}
+
+// GetKeyHandoffs returns every handoff of the keys issued under the ticket, in order
+func (s *ValetService) GetKeyHandoffs(ticketID string) []KeyHandoff {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var handoffs []KeyHandoff
+	for _, handoff := range s.handoffs {
+		if handoff.TicketID == ticketID {
+			handoffs = append(handoffs, handoff)
+		}
+	}
+	return handoffs
+}
+
+// GetKeyAudit returns every key handoff the service has recorded, in order
+func (s *ValetService) GetKeyAudit() []KeyHandoff {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	handoffs := make([]KeyHandoff, len(s.handoffs))
+	copy(handoffs, s.handoffs)
+	return handoffs
+}
+
+// retrievalRequest describes the queue entry at index i. Attendants fetch cars in parallel,
+// so the wait grows by one retrieval time per full round of attendants ahead. The caller
+// must hold s.mu.
+func (s *ValetService) retrievalRequest(i int) RetrievalRequest {
+	request := s.queue[i]
+	rounds := i/max(len(s.attendants), 1) + 1
+	request.Position = i + 1
+	request.EstimatedWait = time.Duration(rounds) * s.retrievalTime
+	return request
+}
+
+// recordHandoff appends a key handoff to the audit. The caller must hold s.mu.
+func (s *ValetService) recordHandoff(ticket ValetTicket, from, to string, at time.Time) {
+	s.handoffs = append(s.handoffs, KeyHandoff{TicketID: ticket.ID, Plate: ticket.Plate, From: from, To: to, At: at})
+}
+
+// lotByID returns the service's lot with the given ID. The caller must hold s.mu.
+func (s *ValetService) lotByID(id string) *ParkingLot {
+	for _, lot := range s.lots {
+		if lot.GetID() == id {
+			return lot
+		}
+	}
+	return nil
+}
+
+// hookName names a key cabinet hook in the audit
+func hookName(hook int) string {
+	return fmt.Sprintf("Cabinet hook %d", hook)
+}
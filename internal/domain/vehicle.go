@@ -0,0 +1,292 @@
+package domain
+
+import "fmt"
+
+// VehicleType is the kind of vehicle parked in a slot
+type VehicleType int
+
+const (
+	VehicleCar VehicleType = iota // The zero value, so every existing Car is a car
+	VehicleMotorcycle
+	VehicleBicycle
+	VehicleVan
+	VehicleBus
+	VehicleTruck
+)
+
+// VehicleTypes lists every vehicle type
+var VehicleTypes = []VehicleType{VehicleCar, VehicleMotorcycle, VehicleBicycle, VehicleVan, VehicleBus, VehicleTruck}
+
+// String returns string representation of VehicleType
+func (t VehicleType) String() string {
+	switch t {
+	case VehicleCar:
+		return "Car"
+	case VehicleMotorcycle:
+		return "Motorcycle"
+	case VehicleBicycle:
+		return "Bicycle"
+	case VehicleVan:
+		return "Van"
+	case VehicleBus:
+		return "Bus"
+	case VehicleTruck:
+		return "Truck"
+	default:
+		return "Unknown"
+	}
+}
+
+// Dimensions are a vehicle's outside measurements in metres
+type Dimensions struct {
+	Length float64
+	Width  float64
+	Height float64
+}
+
+// VehicleSpec is what a type of vehicle needs from a lot
+type VehicleSpec struct {
+	Size       CarSize    // Size used to match the vehicle to slots, see SlotSize.Fits
+	Dimensions Dimensions // Typical measurements, used when the vehicle does not give its own
+}
+
+// VehicleSpecs holds the slot requirements and typical dimensions of each vehicle type other
+// than cars, whose size is given per car
+var VehicleSpecs = map[VehicleType]VehicleSpec{
+	VehicleMotorcycle: {Size: Small, Dimensions: Dimensions{Length: 2.2, Width: 0.8, Height: 1.2}},
+	VehicleBicycle:    {Size: Small, Dimensions: Dimensions{Length: 1.8, Width: 0.6, Height: 1.1}},
+	VehicleVan:        {Size: Large, Dimensions: Dimensions{Length: 5.5, Width: 2.0, Height: 2.5}},
+	VehicleBus:        {Size: Large, Dimensions: Dimensions{Length: 12.0, Width: 2.55, Height: 3.2}},
+	VehicleTruck:      {Size: Large, Dimensions: Dimensions{Length: 10.0, Width: 2.5, Height: 3.8}},
+}
+
+// carDimensions are the typical measurements of a car of each size
+var carDimensions = map[CarSize]Dimensions{
+	Small:  {Length: 3.7, Width: 1.7, Height: 1.5},
+	Medium: {Length: 4.5, Width: 1.8, Height: 1.5},
+	Large:  {Length: 5.0, Width: 2.0, Height: 1.8},
+}
+
+// Vehicle is anything a lot can park. Lots record every vehicle as a Car whose Type says what
+// kind of vehicle it is, so finders and investigations search every type at once.
+type Vehicle interface {
+	GetPlate() string
+	GetType() VehicleType
+	GetDimensions() Dimensions
+	Record() Car // The details a lot records for the vehicle
+}
+
+// GetPlate implements Vehicle
+func (c Car) GetPlate() string {
+	return c.Plate
+}
+
+// GetType implements Vehicle
+func (c Car) GetType() VehicleType {
+	return c.Type
+}
+
+// GetDimensions implements Vehicle with the typical measurements of the car's type and size
+func (c Car) GetDimensions() Dimensions {
+	if c.Type == VehicleCar {
+		return carDimensions[c.Size]
+	}
+	return VehicleSpecs[c.Type].Dimensions
+}
+
+// Record implements Vehicle
+func (c Car) Record() Car {
+	return c
+}
+
+// kind describes the car for messages, e.g. "Large car" or "Bus"
+func (c Car) kind() string {
+	if c.Type == VehicleCar {
+		return c.Size.String() + " car"
+	}
+	return c.Type.String()
+}
+
+// Motorcycle is a motorcycle or scooter
+type Motorcycle struct {
+	Plate      string
+	Make       string
+	Color      string
+	IsElectric bool
+}
+
+// Bicycle is a bicycle, identified by the tag issued at the rack since it has no plate
+type Bicycle struct {
+	Tag        string
+	Make       string
+	Color      string
+	IsElectric bool
+}
+
+// Van is a van or minibus
+type Van struct {
+	Plate      string
+	Make       string
+	Color      string
+	IsElectric bool
+}
+
+// Bus is a coach or bus
+type Bus struct {
+	Plate      string
+	Make       string
+	Color      string
+	IsElectric bool
+}
+
+// Truck is a lorry or other heavy goods vehicle
+type Truck struct {
+	Plate      string
+	Make       string
+	Color      string
+	IsElectric bool
+}
+
+// recordOf builds the Car record of a vehicle of the given type
+func recordOf(vehicleType VehicleType, plate, make, color string, electric bool) Car {
+	return Car{Plate: plate, Make: make, Color: color, Size: VehicleSpecs[vehicleType].Size, Type: vehicleType, IsElectric: electric}
+}
+
+// GetPlate implements Vehicle
+func (m Motorcycle) GetPlate() string {
+	return m.Plate
+}
+
+// GetType implements Vehicle
+func (m Motorcycle) GetType() VehicleType {
+	return VehicleMotorcycle
+}
+
+// GetDimensions implements Vehicle with the typical measurements of a motorcycle
+func (m Motorcycle) GetDimensions() Dimensions {
+	return VehicleSpecs[VehicleMotorcycle].Dimensions
+}
+
+// Record implements Vehicle
+func (m Motorcycle) Record() Car {
+	return recordOf(VehicleMotorcycle, m.Plate, m.Make, m.Color, m.IsElectric)
+}
+
+// GetPlate implements Vehicle with the rack tag
+func (b Bicycle) GetPlate() string {
+	return b.Tag
+}
+
+// GetType implements Vehicle
+func (b Bicycle) GetType() VehicleType {
+	return VehicleBicycle
+}
+
+// GetDimensions implements Vehicle with the typical measurements of a bicycle
+func (b Bicycle) GetDimensions() Dimensions {
+	return VehicleSpecs[VehicleBicycle].Dimensions
+}
+
+// Record implements Vehicle
+func (b Bicycle) Record() Car {
+	return recordOf(VehicleBicycle, b.Tag, b.Make, b.Color, b.IsElectric)
+}
+
+// GetPlate implements Vehicle
+func (v Van) GetPlate() string {
+	return v.Plate
+}
+
+// GetType implements Vehicle
+func (v Van) GetType() VehicleType {
+	return VehicleVan
+}
+
+// GetDimensions implements Vehicle with the typical measurements of a van
+func (v Van) GetDimensions() Dimensions {
+	return VehicleSpecs[VehicleVan].Dimensions
+}
+
+// Record implements Vehicle
+func (v Van) Record() Car {
+	return recordOf(VehicleVan, v.Plate, v.Make, v.Color, v.IsElectric)
+}
+
+// GetPlate implements Vehicle
+func (b Bus) GetPlate() string {
+	return b.Plate
+}
+
+// GetType implements Vehicle
+func (b Bus) GetType() VehicleType {
+	return VehicleBus
+}
+
+// GetDimensions implements Vehicle with the typical measurements of a bus
+func (b Bus) GetDimensions() Dimensions {
+	return VehicleSpecs[VehicleBus].Dimensions
+}
+
+// Record implements Vehicle
+func (b Bus) Record() Car {
+	return recordOf(VehicleBus, b.Plate, b.Make, b.Color, b.IsElectric)
+}
+
+// GetPlate implements Vehicle
+func (t Truck) GetPlate() string {
+	return t.Plate
+}
+
+// GetType implements Vehicle
+func (t Truck) GetType() VehicleType {
+	return VehicleTruck
+}
+
+// GetDimensions implements Vehicle with the typical measurements of a truck
+func (t Truck) GetDimensions() Dimensions {
+	return VehicleSpecs[VehicleTruck].Dimensions
+}
+
+// Record implements Vehicle
+func (t Truck) Record() Car {
+	return recordOf(VehicleTruck, t.Plate, t.Make, t.Color, t.IsElectric)
+}
+
+// ParkVehicle parks any kind of vehicle and returns its ticket. The vehicle is placed in the
+// tightest free slot its type allows.
+func (p *ParkingLot) ParkVehicle(vehicle Vehicle) (Ticket, error) {
+	if vehicle == nil {
+		return Ticket{}, fmt.Errorf("%w: no vehicle given", ErrInvalidCar)
+	}
+	return p.parkAndNotify(parkRequest{car: vehicle.Record()})
+}
+
+// UnparkVehicle removes a vehicle by plate and returns the receipt for its stay
+func (p *ParkingLot) UnparkVehicle(vehicle Vehicle) (Receipt, error) {
+	if vehicle == nil {
+		return Receipt{}, fmt.Errorf("%w: no vehicle given", ErrInvalidCar)
+	}
+	return p.UnparkWithReceipt(vehicle.Record())
+}
+
+// FindVehiclesByType returns the parked vehicles of the given type in slot order
+func (p *ParkingLot) FindVehiclesByType(vehicleType VehicleType) []Car {
+	return carsOf(p.Query(NewQuery().Where(TypeIs(vehicleType))))
+}
+
+// CanFitVehicle reports whether the vehicle could park in the lot now, false for a nil vehicle
+func (p *ParkingLot) CanFitVehicle(vehicle Vehicle) bool {
+	return p.GetAvailableSpacesForVehicle(vehicle) > 0
+}
+
+// ParkVehicle parks a vehicle in the nearest lot with a free slot its type allows. Electric
+// vehicles go to the nearest lot where they can charge, if any.
+func (a *ParkingAttendant) ParkVehicle(lots []*ParkingLot, vehicle Vehicle) (Ticket, error) {
+	if len(lots) == 0 {
+		return Ticket{}, ErrNoLotsAvailable
+	}
+	if vehicle == nil {
+		return Ticket{}, fmt.Errorf("%w: no vehicle given", ErrInvalidCar)
+	}
+	return a.parkWithStrategy(lots, parkRequest{car: vehicle.Record()}, ChargingFirstStrategy{})
+}
@@ -0,0 +1,217 @@
+package unit
+
+import (
+	"errors"
+	"fmt"
+	"parking-lot-system/internal/domain"
+	"testing"
+	"time"
+)
+
+func chargingTestLot(clock domain.Clock) *domain.ParkingLot {
+	return domain.NewParkingLot(4,
+		domain.WithClock(clock),
+		domain.WithChargingSlots(2, 3),
+		domain.WithCharger(domain.NewSimulatedCharger(7, 40)),
+		domain.WithTariff(domain.StandardTariff{
+			HourlyRates: map[domain.CarSize]domain.Money{domain.Small: 200},
+			EnergyRate:  30,
+		}),
+	)
+}
+
+func TestParkingLot_Park_ShouldKeepChargingSlotsForElectricVehicles(t *testing.T) {
+	clock := domain.NewFakeClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	lot := chargingTestLot(clock)
+
+	lot.Park(domain.Car{Plate: "KA01EV0001", IsElectric: true})
+	lot.Park(domain.Car{Plate: "KA01PT0001"})
+	if lot.FindCar("KA01EV0001") != 2 || lot.FindCar("KA01PT0001") != 0 {
+		t.Errorf("Expected the EV in slot 2 and the petrol car in slot 0, got %d and %d",
+			lot.FindCar("KA01EV0001"), lot.FindCar("KA01PT0001"))
+	}
+
+	// Once the plain slots are taken other cars may use a charger slot
+	lot.Park(domain.Car{Plate: "KA01PT0002"})
+	lot.Park(domain.Car{Plate: "KA01PT0003"})
+	if lot.FindCar("KA01PT0003") != 3 || lot.GetAvailableChargingSpaces() != 0 {
+		t.Errorf("Expected the last petrol car in the free charging slot, got %d", lot.FindCar("KA01PT0003"))
+	}
+	if session, charging := lot.GetChargingSession("KA01PT0003"); charging {
+		t.Errorf("Expected no session for a petrol car, got %+v", session)
+	}
+	if found := lot.Query(domain.NewQuery().Where(domain.ElectricIs(true))); len(found) != 1 {
+		t.Errorf("Expected one electric vehicle, got %d", len(found))
+	}
+}
+
+func TestParkingLot_Unpark_ShouldBillChargedEnergy(t *testing.T) {
+	clock := domain.NewFakeClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	lot := chargingTestLot(clock)
+	lot.ParkVehicle(domain.Car{Plate: "KA01EV0001", IsElectric: true})
+
+	clock.Advance(2 * time.Hour)
+	session, charging := lot.GetChargingSession("KA01EV0001")
+	if !charging || !session.Active() || session.KWh != 14 {
+		t.Errorf("Expected 14 kWh so far, got %+v", session)
+	}
+
+	// The battery limit caps the energy at 40 kWh
+	clock.Advance(8 * time.Hour)
+	receipt, err := lot.UnparkWithReceipt(domain.Car{Plate: "KA01EV0001"})
+	if err != nil {
+		t.Fatalf("Expected the EV to leave, got %v", err)
+	}
+	if receipt.EnergyKWh != 40 || receipt.Total != 10*200+40*30 {
+		t.Errorf("Expected 40 kWh and a total of 32.00, got %v kWh and %s", receipt.EnergyKWh, receipt.Total)
+	}
+	if sessions := lot.GetChargingSessions(); len(sessions) != 1 || sessions[0].Active() || sessions[0].KWh != 40 {
+		t.Errorf("Expected the closed session, got %+v", sessions)
+	}
+}
+
+func TestParkingLot_StopCharging_ShouldBillEnergyUpToUnplugging(t *testing.T) {
+	clock := domain.NewFakeClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	lot := chargingTestLot(clock)
+	lot.ParkVehicle(domain.Van{Plate: "KA01VN0001", IsElectric: true})
+
+	clock.Advance(time.Hour)
+	if _, err := lot.StopCharging("KA01VN0001"); err != nil {
+		t.Fatalf("Expected charging to stop, got %v", err)
+	}
+	if _, err := lot.StopCharging("KA01VN0001"); !errors.Is(err, domain.ErrNotCharging) {
+		t.Errorf("Expected ErrNotCharging when stopping twice, got %v", err)
+	}
+
+	clock.Advance(3 * time.Hour)
+	receipt, _ := lot.UnparkVehicle(domain.Van{Plate: "KA01VN0001"})
+	if receipt.EnergyKWh != 7 {
+		t.Errorf("Expected 7 kWh billed, got %v", receipt.EnergyKWh)
+	}
+}
+
+func TestParkingAttendant_ParkVehicle_ShouldPreferLotsWhereEVsCanCharge(t *testing.T) {
+	clock := domain.NewFakeClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	plain := domain.NewParkingLot(10)
+	charging := chargingTestLot(clock)
+	attendant := domain.NewParkingAttendant("John Doe")
+
+	if _, err := attendant.ParkVehicle([]*domain.ParkingLot{plain, charging}, domain.Car{Plate: "KA01EV0001", IsElectric: true}); err != nil {
+		t.Fatalf("Expected the EV to park, got %v", err)
+	}
+	if charging.FindCar("KA01EV0001") != 2 {
+		t.Errorf("Expected the EV at a charger, got slot %d", charging.FindCar("KA01EV0001"))
+	}
+	attendant.ParkVehicle([]*domain.ParkingLot{plain, charging}, domain.Car{Plate: "KA01PT0001"})
+	if plain.FindCar("KA01PT0001") != 0 {
+		t.Errorf("Expected a petrol car in the nearest lot")
+	}
+}
+
+func TestSimulatedCharger_ShouldServeEveryLevelOfAGarage(t *testing.T) {
+	clock := domain.NewFakeClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	garage := domain.NewGarage("N", "Garage North", []domain.LevelSpec{
+		{Number: 1, Rows: []domain.RowSpec{{Name: "A", Slots: 2}}},
+		{Number: 2, Rows: []domain.RowSpec{{Name: "A", Slots: 2}}},
+	}, domain.WithClock(clock), domain.WithChargingSlots(0), domain.WithCharger(domain.NewSimulatedCharger(7, 0)))
+
+	for i, lot := range garage.GetLots() {
+		if _, err := lot.ParkVehicle(domain.Car{Plate: fmt.Sprintf("KA01EV000%d", i), IsElectric: true}); err != nil {
+			t.Fatalf("Expected the EV to park on level %d, got %v", i+1, err)
+		}
+	}
+	clock.Advance(time.Hour)
+	for i, lot := range garage.GetLots() {
+		session, charging := lot.GetChargingSession(fmt.Sprintf("KA01EV000%d", i))
+		if !charging || !session.Active() || session.KWh != 7 {
+			t.Errorf("Expected 7 kWh charged on level %d, got %+v", i+1, session)
+		}
+	}
+}
+
+// faultyCharger refuses to start any session
+type faultyCharger struct{}
+
+func (faultyCharger) Start(lotID string, slotID int, at time.Time) error {
+	return errors.New("ground fault")
+}
+func (faultyCharger) Meter(lotID string, slotID int, at time.Time) float64 { return 0 }
+func (faultyCharger) Stop(lotID string, slotID int, at time.Time)          {}
+
+func TestParkingLot_Park_ShouldRecordChargerFaults(t *testing.T) {
+	clock := domain.NewFakeClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	lot := domain.NewParkingLot(2, domain.WithClock(clock), domain.WithChargingSlots(0), domain.WithCharger(faultyCharger{}))
+
+	if err := lot.TryPark(domain.Car{Plate: "KA01EV0001", IsElectric: true}); err != nil {
+		t.Fatalf("Expected the EV to park despite the fault, got %v", err)
+	}
+	session, exists := lot.GetChargingSession("KA01EV0001")
+	if !exists || session.Active() || session.Fault != "ground fault" {
+		t.Errorf("Expected an ended session recording the fault, got %+v", session)
+	}
+	if _, err := lot.StopCharging("KA01EV0001"); !errors.Is(err, domain.ErrNotCharging) {
+		t.Errorf("Expected ErrNotCharging for a session that never started, got %v", err)
+	}
+}
+
+func TestRestoreSnapshot_ShouldResumeChargingSessions(t *testing.T) {
+	clock := domain.NewFakeClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	charger := domain.NewSimulatedCharger(7, 40)
+	lot := domain.NewParkingLot(2, domain.WithClock(clock), domain.WithChargingSlots(0, 1), domain.WithCharger(charger))
+	empty := lot.Snapshot()
+	lot.ParkVehicle(domain.Car{Plate: "KA01EV0001", IsElectric: true})
+	clock.Advance(time.Hour)
+
+	restored, err := domain.RestoreSnapshot(lot.Snapshot(), domain.WithClock(clock), domain.WithChargingSlots(0, 1), domain.WithCharger(charger))
+	if err != nil {
+		t.Fatalf("Expected the snapshot to restore, got %v", err)
+	}
+	clock.Advance(time.Hour)
+	receipt, _ := restored.UnparkVehicle(domain.Car{Plate: "KA01EV0001"})
+	if receipt.EnergyKWh != 14 {
+		t.Errorf("Expected 14 kWh billed across the restore, got %v", receipt.EnergyKWh)
+	}
+	restored.ParkVehicle(domain.Car{Plate: "KA01EV0002", IsElectric: true})
+	if session, _ := restored.GetChargingSession("KA01EV0002"); !session.Active() || session.Fault != "" {
+		t.Errorf("Expected the next EV to charge in the freed slot, got %+v", session)
+	}
+
+	// A lot restored from before the EV parked stops the session it does not know of
+	lot.ParkVehicle(domain.Car{Plate: "KA01EV0003", IsElectric: true})
+	if _, err := domain.RestoreSnapshot(empty, domain.WithClock(clock), domain.WithChargingSlots(0, 1), domain.WithCharger(charger)); err != nil {
+		t.Fatalf("Expected the empty snapshot to restore, got %v", err)
+	}
+	if kwh := charger.Meter(lot.GetID(), lot.FindCar("KA01EV0003"), clock.Now().Add(time.Hour)); kwh != 0 {
+		t.Errorf("Expected the orphaned session to be stopped, got %v kWh", kwh)
+	}
+}
+
+func TestReplayJournal_ShouldRebuildChargingSessions(t *testing.T) {
+	journal, journalPath := openTestJournal(t)
+	clock := domain.NewFakeClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	charger := domain.NewSimulatedCharger(7, 40)
+	lot := domain.NewParkingLot(3, domain.WithClock(clock), domain.WithChargingSlots(0, 1, 2), domain.WithCharger(charger))
+	lot.AttachJournal(journal)
+
+	lot.ParkVehicle(domain.Car{Plate: "KA01EV0001", IsElectric: true})
+	lot.ParkVehicle(domain.Car{Plate: "KA01EV0002", IsElectric: true})
+	lot.ParkVehicle(domain.Car{Plate: "KA01EV0003", IsElectric: true})
+	clock.Advance(time.Hour)
+	lot.StopCharging("KA01EV0002")
+	lot.UnparkVehicle(domain.Car{Plate: "KA01EV0003"})
+	clock.Advance(time.Hour)
+
+	replayed, err := domain.ReplayJournal(journalPath, domain.WithClock(clock), domain.WithChargingSlots(0, 1, 2), domain.WithCharger(charger))
+	if err != nil {
+		t.Fatalf("Expected replay to succeed, got %v", err)
+	}
+	if session, _ := replayed.GetChargingSession("KA01EV0001"); !session.Active() || session.KWh != 14 {
+		t.Errorf("Expected the first EV still charging with 14 kWh, got %+v", session)
+	}
+	if session, _ := replayed.GetChargingSession("KA01EV0002"); session.Active() || session.KWh != 7 {
+		t.Errorf("Expected the second EV unplugged at 7 kWh, got %+v", session)
+	}
+	if sessions := replayed.GetChargingSessions(); len(sessions) != 1 || sessions[0].Plate != "KA01EV0003" || sessions[0].KWh != 7 {
+		t.Errorf("Expected the departed EV's session, got %+v", sessions)
+	}
+}
@@ -0,0 +1,55 @@
+package unit
+
+import (
+	"parking-lot-system/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestFakeClock_ShouldOnlyMoveWhenAdvanced(t *testing.T) {
+	start := time.Date(2025, 7, 9, 10, 0, 0, 0, time.UTC)
+	clock := domain.NewFakeClock(start)
+
+	if !clock.Now().Equal(start) {
+		t.Errorf("Expected %v, got %v", start, clock.Now())
+	}
+	clock.Advance(time.Hour)
+	if !clock.Now().Equal(start.Add(time.Hour)) {
+		t.Errorf("Expected clock to advance one hour, got %v", clock.Now())
+	}
+}
+
+func TestParkingLot_ShouldStampTicketsAndConflictsFromInjectedClock(t *testing.T) {
+	start := time.Date(2025, 7, 9, 10, 0, 0, 0, time.UTC)
+	clock := domain.NewFakeClock(start)
+	lot := domain.NewParkingLot(10, domain.WithClock(clock))
+	registry := domain.NewPlateRegistry(lot)
+	car := domain.Car{Plate: "MH12AB1234", Make: "Toyota", Color: "Blue"}
+
+	ticket, _ := lot.ParkWithTicket(car)
+	clock.Advance(5 * time.Minute)
+	lot.Park(car)
+
+	if !ticket.EntryTime.Equal(start) {
+		t.Errorf("Expected ticket entry time %v, got %v", start, ticket.EntryTime)
+	}
+	conflicts := registry.GetConflicts()
+	if len(conflicts) != 1 || !conflicts[0].DetectedAt.Equal(start.Add(5*time.Minute)) {
+		t.Errorf("Expected conflict stamped at %v, got %+v", start.Add(5*time.Minute), conflicts)
+	}
+}
+
+func TestParkingLot_FindCarsParkedInLastMinutes_ShouldFollowClockBoundary(t *testing.T) {
+	clock := domain.NewFakeClock(time.Date(2025, 7, 9, 10, 0, 0, 0, time.UTC))
+	lot := domain.NewParkingLot(10, domain.WithClock(clock))
+	lot.Park(domain.Car{Plate: "MH12AB1234"})
+
+	clock.Advance(29 * time.Minute)
+	if len(lot.FindCarsParkedInLastMinutes(30)) != 1 {
+		t.Errorf("Expected car parked 29 minutes ago to be recent")
+	}
+	clock.Advance(2 * time.Minute)
+	if len(lot.FindCarsParkedInLastMinutes(30)) != 0 {
+		t.Errorf("Expected car parked 31 minutes ago not to be recent")
+	}
+}
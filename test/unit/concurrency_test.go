@@ -0,0 +1,132 @@
+package unit
+
+import (
+	"fmt"
+	"parking-lot-system/internal/domain"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// countingOwner is a thread-safe owner observer for concurrent tests
+type countingOwner struct {
+	full      atomic.Int32
+	available atomic.Int32
+}
+
+func (o *countingOwner) OnLotFull(message string) {
+	o.full.Add(1)
+}
+
+func (o *countingOwner) OnSpaceAvailable(message string) {
+	o.available.Add(1)
+}
+
+// Run with `go test -race` to also check for data races
+func TestParkingLot_ConcurrentPark_ShouldNeverExceedCapacity(t *testing.T) {
+	const capacity = 50
+	const gates = 200
+	lot := domain.NewParkingLot(capacity)
+	owner := &countingOwner{}
+	lot.AddOwnerObserver(owner)
+
+	var parked atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < gates; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			car := domain.Car{Plate: fmt.Sprintf("MH12AB%04d", i), Make: "Toyota", Color: "Blue"}
+			if lot.Park(car) {
+				parked.Add(1)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if parked.Load() != capacity {
+		t.Errorf("Expected exactly %d successful parks, got %d", capacity, parked.Load())
+	}
+	if lot.GetParkedCarsCount() != capacity {
+		t.Errorf("Expected %d parked cars, got %d", capacity, lot.GetParkedCarsCount())
+	}
+	if owner.full.Load() != 1 {
+		t.Errorf("Expected owner to be notified once, got %d", owner.full.Load())
+	}
+}
+
+func TestParkingLot_ConcurrentParkAndUnpark_ShouldKeepSlotsConsistent(t *testing.T) {
+	const capacity = 20
+	lot := domain.NewParkingLot(capacity)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			car := domain.Car{Plate: fmt.Sprintf("MH12AB%04d", i), Make: "Honda", Color: "White"}
+			for j := 0; j < 20; j++ {
+				if lot.Park(car) {
+					lot.FindCarsByColor("White")
+					lot.Unpark(car)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if lot.GetParkedCarsCount() != 0 {
+		t.Errorf("Expected empty lot, got %d cars", lot.GetParkedCarsCount())
+	}
+	for _, slot := range lot.GetSlots() {
+		if slot.IsOccupied() {
+			t.Errorf("Expected slot %d to be free", slot.ID)
+		}
+	}
+}
+
+func TestParkingAttendant_ConcurrentStrategies_ShouldNeverOverfillLots(t *testing.T) {
+	lots := []*domain.ParkingLot{
+		domain.NewParkingLot(10),
+		domain.NewParkingLot(15),
+		domain.NewParkingLot(5),
+	}
+	attendants := []*domain.ParkingAttendant{
+		domain.NewParkingAttendant("Gate A"),
+		domain.NewParkingAttendant("Gate B"),
+	}
+
+	var parked atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 90; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			attendant := attendants[i%len(attendants)]
+			car := domain.Car{Plate: fmt.Sprintf("KA01XY%04d", i), Make: "BMW", Color: "Black", Size: domain.Large}
+
+			var ok bool
+			switch i % 3 {
+			case 0:
+				ok = attendant.ParkCarEvenly(lots, car)
+			case 1:
+				ok = attendant.ParkHandicapCar(lots, car)
+			default:
+				ok = attendant.ParkLargeCar(lots, car)
+			}
+			if ok {
+				parked.Add(1)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if parked.Load() != 30 {
+		t.Errorf("Expected all 30 spaces to be used, got %d", parked.Load())
+	}
+	for i, lot := range lots {
+		if !lot.IsFull() || lot.GetAvailableSpaces() != 0 {
+			t.Errorf("Expected lot %d to be exactly full, %d spaces left", i, lot.GetAvailableSpaces())
+		}
+	}
+}
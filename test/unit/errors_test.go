@@ -0,0 +1,64 @@
+package unit
+
+import (
+	"errors"
+	"parking-lot-system/internal/domain"
+	"testing"
+)
+
+func TestParkingLot_TryPark_ShouldReturnErrLotFull_WhenNoSpace(t *testing.T) {
+	lot := domain.NewParkingLot(1)
+	lot.Park(domain.Car{Plate: "MH12AB1234", Make: "Toyota", Color: "Blue"})
+
+	err := lot.TryPark(domain.Car{Plate: "MH12AB5678", Make: "Honda", Color: "White"})
+	if !errors.Is(err, domain.ErrLotFull) {
+		t.Errorf("Expected ErrLotFull, got %v", err)
+	}
+}
+
+func TestParkingLot_TryPark_ShouldReturnErrCarAlreadyParked_WhenPlateParked(t *testing.T) {
+	lot := domain.NewParkingLot(5)
+	car := domain.Car{Plate: "MH12AB1234", Make: "Toyota", Color: "Blue"}
+	lot.Park(car)
+
+	if err := lot.TryParkInRow(car, "B", false); !errors.Is(err, domain.ErrCarAlreadyParked) {
+		t.Errorf("Expected ErrCarAlreadyParked, got %v", err)
+	}
+}
+
+func TestParkingLot_TryPark_ShouldReturnErrInvalidCar_WhenPlateEmpty(t *testing.T) {
+	lot := domain.NewParkingLot(5)
+
+	if err := lot.TryPark(domain.Car{Make: "Toyota", Color: "Blue"}); !errors.Is(err, domain.ErrInvalidCar) {
+		t.Errorf("Expected ErrInvalidCar, got %v", err)
+	}
+}
+
+func TestParkingLot_TryUnpark_ShouldReturnErrCarNotFound_WhenCarNotParked(t *testing.T) {
+	lot := domain.NewParkingLot(5)
+
+	err := lot.TryUnpark(domain.Car{Plate: "MH12AB1234"})
+	if !errors.Is(err, domain.ErrCarNotFound) {
+		t.Errorf("Expected ErrCarNotFound, got %v", err)
+	}
+}
+
+func TestParkingAttendant_TryStrategies_ShouldReportReason(t *testing.T) {
+	attendant := domain.NewParkingAttendant("John Doe")
+	car := domain.Car{Plate: "MH12AB1234", Make: "Toyota", Color: "Blue", Size: domain.Large}
+
+	if err := attendant.TryParkCarEvenly(nil, car); !errors.Is(err, domain.ErrNoLotsAvailable) {
+		t.Errorf("Expected ErrNoLotsAvailable, got %v", err)
+	}
+
+	lots := []*domain.ParkingLot{domain.NewParkingLot(1), domain.NewParkingLot(1)}
+	attendant.ParkCarEvenly(lots, domain.Car{Plate: "MH12AB0001"})
+	attendant.ParkCarEvenly(lots, domain.Car{Plate: "MH12AB0002"})
+
+	if err := attendant.TryParkLargeCar(lots, car); !errors.Is(err, domain.ErrLotFull) {
+		t.Errorf("Expected ErrLotFull, got %v", err)
+	}
+	if err := attendant.TryParkHandicapCar(lots, domain.Car{Plate: "MH12AB0001"}); !errors.Is(err, domain.ErrLotFull) {
+		t.Errorf("Expected ErrLotFull, got %v", err)
+	}
+}
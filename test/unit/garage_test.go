@@ -0,0 +1,129 @@
+package unit
+
+import (
+	"errors"
+	"parking-lot-system/internal/domain"
+	"testing"
+)
+
+func northGarage() *domain.Garage {
+	return domain.NewGarage("N", "Garage North", []domain.LevelSpec{
+		{Number: 1, Rows: []domain.RowSpec{{Name: "A", Slots: 2}}},
+		{Number: 2, Rows: []domain.RowSpec{{Name: "A", Slots: 3}, {Name: "B", Slots: 15}}},
+	})
+}
+
+func TestGarage_ShouldReportCapacityPerLevel(t *testing.T) {
+	garage := northGarage()
+
+	if garage.GetCapacity() != 20 || garage.GetAvailableSpaces() != 20 {
+		t.Errorf("Expected 20 free slots, got %d of %d", garage.GetAvailableSpaces(), garage.GetCapacity())
+	}
+	level, err := garage.GetLevel(2)
+	if err != nil {
+		t.Fatalf("Expected level 2, got %v", err)
+	}
+	if level.GetCapacity() != 18 || level.GetName() != "Level 2" || level.GetLot().GetID() != "N-L2" {
+		t.Errorf("Unexpected level: %d slots, %q, %q", level.GetCapacity(), level.GetName(), level.GetLot().GetID())
+	}
+	if rows := level.GetLot().GetRows(); len(rows) != 2 || rows[0] != "A" || rows[1] != "B" {
+		t.Errorf("Expected rows A and B, got %v", rows)
+	}
+	if _, err := garage.GetLevel(7); !errors.Is(err, domain.ErrUnknownLevel) {
+		t.Errorf("Expected ErrUnknownLevel, got %v", err)
+	}
+}
+
+func TestParkingLot_ParkInRow_ShouldUseTheRowsSlots(t *testing.T) {
+	lot := domain.NewParkingLot(4, domain.WithRows(domain.RowSpec{Name: "A", Slots: 2}, domain.RowSpec{Name: "B", Slots: 2}))
+
+	if err := lot.TryParkInRow(domain.Car{Plate: "B1"}, "B", false); err != nil {
+		t.Fatalf("Expected car to park in row B, got %v", err)
+	}
+	if lot.FindCar("B1") != 2 {
+		t.Errorf("Expected the first slot of row B, got %d", lot.FindCar("B1"))
+	}
+	lot.ParkInRow(domain.Car{Plate: "B2"}, "B", false)
+	if err := lot.TryParkInRow(domain.Car{Plate: "B3"}, "B", false); !errors.Is(err, domain.ErrLotFull) {
+		t.Errorf("Expected a full row to report ErrLotFull, got %v", err)
+	}
+	if err := lot.TryParkInRow(domain.Car{Plate: "Z1"}, "Z", false); !errors.Is(err, domain.ErrUnknownRow) {
+		t.Errorf("Expected ErrUnknownRow, got %v", err)
+	}
+
+	lot.Park(domain.Car{Plate: "ANY"})
+	if location, _ := lot.Locate("ANY"); location.Row != "A" || location.SlotNumber != 1 {
+		t.Errorf("Expected an unrouted car to be recorded under its slot's row, got %+v", location)
+	}
+}
+
+func TestParkingAttendant_ParkCarOnLevel_ShouldRouteToTheLevel(t *testing.T) {
+	garage := northGarage()
+	attendant := domain.NewParkingAttendant("John Doe")
+
+	ticket, err := attendant.ParkCarOnLevel(garage, 2, "B", domain.Car{Plate: "KA01AB1234"})
+	if err != nil {
+		t.Fatalf("Expected car to park, got %v", err)
+	}
+	if ticket.LotID != "N-L2" {
+		t.Errorf("Expected a ticket for level 2, got %q", ticket.LotID)
+	}
+	if _, err := attendant.ParkCarOnLevel(garage, 9, "", domain.Car{Plate: "X"}); !errors.Is(err, domain.ErrUnknownLevel) {
+		t.Errorf("Expected ErrUnknownLevel, got %v", err)
+	}
+}
+
+func TestParkingAttendant_ParkCarInGarage_ShouldOverflowToNextLevel(t *testing.T) {
+	garage := northGarage()
+	attendant := domain.NewParkingAttendant("John Doe")
+
+	for _, plate := range []string{"P1", "P2", "P3"} {
+		if _, err := attendant.ParkCarInGarage(garage, domain.Car{Plate: plate}); err != nil {
+			t.Fatalf("Expected %s to park, got %v", plate, err)
+		}
+	}
+	levels := garage.GetLevels()
+	if levels[0].GetAvailableSpaces() != 0 || levels[1].GetAvailableSpaces() != 17 {
+		t.Errorf("Expected level 1 to fill before level 2, got %d and %d free",
+			levels[0].GetAvailableSpaces(), levels[1].GetAvailableSpaces())
+	}
+}
+
+func TestLocation_String_ShouldReadLikeTheSignage(t *testing.T) {
+	garage := northGarage()
+	level, _ := garage.GetLevel(2)
+	lot := level.GetLot()
+	for i := 0; i < 3+13; i++ {
+		lot.Park(domain.Car{Plate: string(rune('a'+i)) + "X"})
+	}
+	lot.ParkInRow(domain.Car{Plate: "KA01AB1234", Make: "Toyota", Color: "Blue"}, "B", false)
+
+	location, found := lot.Locate("KA01AB1234")
+	if !found || location.String() != "Garage North, Level 2, Row B, Slot 14" {
+		t.Errorf("Unexpected location %q", location)
+	}
+
+	standalone := domain.NewParkingLot(5)
+	standalone.Park(domain.Car{Plate: "S1"})
+	if location, _ := standalone.Locate("S1"); location.String() != standalone.GetID()+", Slot 0" {
+		t.Errorf("Unexpected standalone location %q", location)
+	}
+}
+
+func TestPoliceDepartment_Investigate_ShouldReportGarageLocations(t *testing.T) {
+	garage := northGarage()
+	attendant := domain.NewParkingAttendant("John Doe")
+	attendant.ParkCarOnLevel(garage, 2, "A", domain.Car{Plate: "KA01AB1234", Make: "Toyota", Color: "Blue"})
+
+	police := domain.NewPoliceDepartment("City Police")
+	results, err := police.Investigate(garage.GetLots(), "make = Toyota")
+	if err != nil || len(results) != 1 {
+		t.Fatalf("Expected one result, got %v, %v", results, err)
+	}
+	if results[0].Location.String() != "Garage North, Level 2, Row A, Slot 1" {
+		t.Errorf("Unexpected location %q", results[0].Location)
+	}
+	if toyotas := police.InvestigateBlueToyotas(garage.GetLots(), attendant); len(toyotas) != 1 || toyotas[0].Location.Level != 2 {
+		t.Errorf("Expected legacy investigations to carry the location, got %+v", toyotas)
+	}
+}
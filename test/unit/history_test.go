@@ -0,0 +1,96 @@
+package unit
+
+import (
+	"parking-lot-system/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestParkingLot_Unpark_ShouldRecordCompletedVisit(t *testing.T) {
+	clock := domain.NewFakeClock(time.Date(2025, 7, 9, 14, 0, 0, 0, time.UTC))
+	store := domain.NewInMemoryHistoryStore()
+	lot := domain.NewParkingLot(5, domain.WithClock(clock), domain.WithHistoryStore(store))
+	attendant := domain.NewParkingAttendant("John Doe")
+	car := domain.Car{Plate: "MH12AB1234", Make: "Honda", Color: "White", Size: domain.Medium}
+
+	attendant.ParkCar(lot, car)
+	clock.Advance(45 * time.Minute)
+	lot.Unpark(car)
+
+	visits := store.Query(domain.VisitFilter{})
+	if len(visits) != 1 {
+		t.Fatalf("Expected 1 visit, got %d", len(visits))
+	}
+	visit := visits[0]
+	if visit.Car() != car || visit.LotID != lot.GetID() || visit.SlotID != 0 || visit.AttendantName != "John Doe" {
+		t.Errorf("Unexpected visit details: %+v", visit)
+	}
+	if visit.ExitTime.Sub(visit.EntryTime) != 45*time.Minute {
+		t.Errorf("Expected 45m visit, got %v", visit.ExitTime.Sub(visit.EntryTime))
+	}
+}
+
+func TestPoliceDepartment_InvestigateVisitHistory_ShouldFindCarsPresentInWindow(t *testing.T) {
+	yesterday := time.Date(2025, 7, 8, 0, 0, 0, 0, time.UTC)
+	clock := domain.NewFakeClock(yesterday.Add(12 * time.Hour))
+	store := domain.NewInMemoryHistoryStore()
+	lot1 := domain.NewParkingLot(5, domain.WithClock(clock), domain.WithHistoryStore(store))
+	lot2 := domain.NewParkingLot(5, domain.WithClock(clock), domain.WithHistoryStore(store))
+	police := domain.NewPoliceDepartment("City Police")
+
+	earlyWhite := domain.Car{Plate: "MH12AB0001", Make: "Honda", Color: "White"}
+	lateWhite := domain.Car{Plate: "MH12AB0002", Make: "Toyota", Color: "White"}
+	blackCar := domain.Car{Plate: "MH12AB0003", Make: "BMW", Color: "Black"}
+	afterWindow := domain.Car{Plate: "MH12AB0004", Make: "Honda", Color: "White"}
+
+	// 12:00-13:00, before the window
+	lot1.Park(earlyWhite)
+	clock.Advance(time.Hour)
+	lot1.Unpark(earlyWhite)
+
+	// 13:30-14:30, overlapping the window
+	clock.Advance(30 * time.Minute)
+	lot2.Park(lateWhite)
+	lot1.Park(blackCar)
+	clock.Advance(time.Hour)
+	lot2.Unpark(lateWhite)
+	lot1.Unpark(blackCar)
+
+	// 16:30-17:00, after the window
+	clock.Advance(2 * time.Hour)
+	lot1.Park(afterWindow)
+	clock.Advance(30 * time.Minute)
+	lot1.Unpark(afterWindow)
+
+	visits := police.InvestigateVisitHistory(store, domain.VisitFilter{
+		From:  yesterday.Add(14 * time.Hour),
+		To:    yesterday.Add(16 * time.Hour),
+		Color: "White",
+	})
+
+	if len(visits) != 1 || visits[0].Plate != lateWhite.Plate {
+		t.Fatalf("Expected only %s, got %+v", lateWhite.Plate, visits)
+	}
+	if visits[0].LotID != lot2.GetID() {
+		t.Errorf("Expected visit in lot %s, got %s", lot2.GetID(), visits[0].LotID)
+	}
+}
+
+func TestInMemoryHistoryStore_Query_ShouldFilterBySizeAndLot(t *testing.T) {
+	store := domain.NewInMemoryHistoryStore()
+	entry := time.Date(2025, 7, 9, 10, 0, 0, 0, time.UTC)
+	store.Record(domain.Visit{Plate: "A1", Size: domain.Large, LotID: "LOT-A", EntryTime: entry.Add(time.Hour), ExitTime: entry.Add(2 * time.Hour)})
+	store.Record(domain.Visit{Plate: "B2", Size: domain.Small, LotID: "LOT-A", EntryTime: entry, ExitTime: entry.Add(time.Hour)})
+	store.Record(domain.Visit{Plate: "C3", Size: domain.Large, LotID: "LOT-B", EntryTime: entry, ExitTime: entry.Add(time.Hour)})
+
+	large := domain.Large
+	visits := store.Query(domain.VisitFilter{Size: &large, LotID: "LOT-A"})
+	if len(visits) != 1 || visits[0].Plate != "A1" {
+		t.Errorf("Expected only A1, got %+v", visits)
+	}
+
+	all := store.Query(domain.VisitFilter{})
+	if len(all) != 3 || !all[0].EntryTime.Equal(entry) || all[2].Plate != "A1" {
+		t.Errorf("Expected visits ordered by entry time, got %+v", all)
+	}
+}
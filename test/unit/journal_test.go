@@ -0,0 +1,207 @@
+package unit
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"parking-lot-system/internal/domain"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func openTestJournal(t *testing.T) (*domain.Journal, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "lot.journal")
+	journal, err := domain.OpenJournal(path)
+	if err != nil {
+		t.Fatalf("Expected journal to open, got %v", err)
+	}
+	t.Cleanup(func() { journal.Close() })
+	return journal, path
+}
+
+func TestJournal_ShouldRecordEveryStateChange(t *testing.T) {
+	journal, path := openTestJournal(t)
+	lot := domain.NewParkingLot(1)
+	if err := lot.AttachJournal(journal); err != nil {
+		t.Fatalf("Expected journal to attach, got %v", err)
+	}
+	car := domain.Car{Plate: "MH12AB1234", Make: "Toyota", Color: "Blue", Size: domain.Small}
+
+	lot.ParkInRow(car, "B", true)
+	lot.Unpark(car)
+
+	records, err := domain.ReadJournal(path)
+	if err != nil {
+		t.Fatalf("Expected journal to be readable, got %v", err)
+	}
+	want := []domain.JournalEventType{
+		domain.JournalLotOpened,
+		domain.JournalPark,
+		domain.JournalRowAssigned,
+		domain.JournalLotFull,
+		domain.JournalUnpark,
+		domain.JournalSpaceAvailable,
+	}
+	if len(records) != len(want) {
+		t.Fatalf("Expected %d records, got %d", len(want), len(records))
+	}
+	for i, record := range records {
+		if record.Type != want[i] {
+			t.Errorf("Record %d: expected %s, got %s", i, want[i], record.Type)
+		}
+	}
+}
+
+func TestReplayJournal_ShouldRebuildLot(t *testing.T) {
+	journal, path := openTestJournal(t)
+	clock := domain.NewFakeClock(time.Date(2025, 7, 9, 10, 0, 0, 0, time.UTC))
+	lot := domain.NewParkingLot(5, domain.WithClock(clock))
+	lot.AttachJournal(journal)
+
+	car1 := domain.Car{Plate: "MH12AB1234", Make: "Toyota", Color: "Blue", Size: domain.Small}
+	car2 := domain.Car{Plate: "MH12AB5678", Make: "Honda", Color: "White", Size: domain.Small}
+	lot.Park(car1)
+	clock.Advance(time.Minute)
+	ticket, _ := lot.ParkWithTicket(car2)
+	lot.ParkInRow(domain.Car{Plate: "MH12AB9999", Size: domain.Small}, "D", true)
+	lot.Unpark(car1)
+
+	replayed, err := domain.ReplayJournal(path)
+	if err != nil {
+		t.Fatalf("Expected replay to succeed, got %v", err)
+	}
+	if replayed.GetID() != lot.GetID() || replayed.GetParkedCarsCount() != 2 {
+		t.Errorf("Expected lot %s with 2 cars, got %s with %d", lot.GetID(), replayed.GetID(), replayed.GetParkedCarsCount())
+	}
+	if replayed.FindCar(car2.Plate) != 1 || !replayed.GetParkingTime(car2.Plate).Equal(clock.Now()) {
+		t.Errorf("Expected car2 in slot 1 parked at %v", clock.Now())
+	}
+	if len(replayed.FindSmallHandicapCarsInRows([]string{"D"})) != 1 {
+		t.Errorf("Expected row assignment to be replayed")
+	}
+	if err := replayed.UnparkByTicket(ticket); err != nil {
+		t.Errorf("Expected ticket to be valid after replay, got %v", err)
+	}
+}
+
+func TestRecoverLot_ShouldApplyJournalAfterSnapshot(t *testing.T) {
+	journal, journalPath := openTestJournal(t)
+	snapshotPath := filepath.Join(t.TempDir(), "lot.json")
+	lot := domain.NewParkingLot(5)
+	lot.AttachJournal(journal)
+
+	lot.Park(domain.Car{Plate: "MH12AB1234"})
+	lot.SaveSnapshotFile(snapshotPath)
+	lot.Park(domain.Car{Plate: "MH12AB5678"})
+	lot.Unpark(domain.Car{Plate: "MH12AB1234"})
+
+	recovered, err := domain.RecoverLot(snapshotPath, journalPath)
+	if err != nil {
+		t.Fatalf("Expected recovery to succeed, got %v", err)
+	}
+	if recovered.GetParkedCarsCount() != 1 || recovered.FindCar("MH12AB5678") != 1 {
+		t.Errorf("Expected only MH12AB5678 in slot 1, got %v", recovered.GetAllParkedCars())
+	}
+}
+
+func TestAttachJournal_ShouldRejectJournalOfAnotherLot(t *testing.T) {
+	journal, path := openTestJournal(t)
+	lot := domain.NewParkingLot(2)
+	lot.AttachJournal(journal)
+	lot.Park(domain.Car{Plate: "MH12AB1234"})
+
+	other := domain.NewParkingLot(2)
+	if err := other.AttachJournal(journal); !errors.Is(err, domain.ErrJournalInUse) {
+		t.Errorf("Expected ErrJournalInUse, got %v", err)
+	}
+	other.Park(domain.Car{Plate: "MH12AB5678"})
+
+	reopened, err := domain.OpenJournal(path)
+	if err != nil {
+		t.Fatalf("Expected journal to reopen, got %v", err)
+	}
+	defer reopened.Close()
+	if err := other.AttachJournal(reopened); !errors.Is(err, domain.ErrJournalInUse) {
+		t.Errorf("Expected ErrJournalInUse after reopening, got %v", err)
+	}
+	replayed, err := domain.ReplayJournal(path)
+	if err != nil || replayed.GetParkedCarsCount() != 1 {
+		t.Errorf("Expected replay of the lot's own car, got %v", err)
+	}
+}
+
+func TestReadJournal_ShouldDetectTampering(t *testing.T) {
+	journal, path := openTestJournal(t)
+	lot := domain.NewParkingLot(5)
+	lot.AttachJournal(journal)
+	lot.Park(domain.Car{Plate: "MH12AB1234", Color: "White"})
+	journal.Close()
+
+	data, _ := os.ReadFile(path)
+	os.WriteFile(path, bytes.Replace(data, []byte("White"), []byte("Black"), 1), 0o644)
+
+	if _, err := domain.ReadJournal(path); !errors.Is(err, domain.ErrJournalCorrupt) {
+		t.Errorf("Expected ErrJournalCorrupt, got %v", err)
+	}
+}
+
+func TestReadJournal_ShouldIgnoreTornFinalWrite(t *testing.T) {
+	journal, path := openTestJournal(t)
+	lot := domain.NewParkingLot(5)
+	lot.AttachJournal(journal)
+	lot.Park(domain.Car{Plate: "MH12AB1234"})
+	journal.Close()
+
+	file, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	file.WriteString(`deadbeef {"seq":3,"type":"pa`)
+	file.Close()
+
+	records, err := domain.ReadJournal(path)
+	if err != nil || len(records) != 2 {
+		t.Errorf("Expected 2 intact records, got %d (%v)", len(records), err)
+	}
+
+	// Reopening drops the torn write so the lot can keep journaling
+	reopened, err := domain.OpenJournal(path)
+	if err != nil {
+		t.Fatalf("Expected journal to reopen, got %v", err)
+	}
+	defer reopened.Close()
+	lot.AttachJournal(reopened)
+	lot.Park(domain.Car{Plate: "MH12AB5678"})
+
+	if records, err := domain.ReadJournal(path); err != nil || len(records) != 3 {
+		t.Errorf("Expected 3 records after reopening, got %d (%v)", len(records), err)
+	}
+}
+
+func TestPoliceDepartment_InvestigateDepartedCars_ShouldFindCarsThatLeft(t *testing.T) {
+	journal, path := openTestJournal(t)
+	clock := domain.NewFakeClock(time.Date(2025, 7, 9, 14, 0, 0, 0, time.UTC))
+	lot := domain.NewParkingLot(5, domain.WithClock(clock))
+	lot.AttachJournal(journal)
+	police := domain.NewPoliceDepartment("City Police")
+	attendant := domain.NewParkingAttendant("John Doe")
+
+	whiteCar := domain.Car{Plate: "MH12AB1234", Make: "Honda", Color: "White"}
+	attendant.ParkCar(lot, whiteCar)
+	clock.Advance(30 * time.Minute)
+	lot.Unpark(whiteCar)
+	clock.Advance(3 * time.Hour)
+	lot.Park(domain.Car{Plate: "MH12AB5678"})
+	lot.Unpark(domain.Car{Plate: "MH12AB5678"})
+
+	from := time.Date(2025, 7, 9, 14, 0, 0, 0, time.UTC)
+	departed, err := police.InvestigateDepartedCars(path, from, from.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("Expected journal to be readable, got %v", err)
+	}
+	if len(departed) != 1 || departed[0].Car.Plate != whiteCar.Plate {
+		t.Fatalf("Expected only the white car, got %+v", departed)
+	}
+	if departed[0].AttendantName != "John Doe" || departed[0].ExitTime.Sub(departed[0].EntryTime) != 30*time.Minute {
+		t.Errorf("Unexpected visit details: %+v", departed[0])
+	}
+}
@@ -0,0 +1,105 @@
+package unit
+
+import (
+	"errors"
+	"parking-lot-system/internal/domain"
+	"testing"
+)
+
+func TestParkingLot_ShouldKeepIDAndNameGivenAtConstruction(t *testing.T) {
+	lot := domain.NewParkingLot(5, domain.WithID("AIR-P1"), domain.WithName("Airport P1"))
+	if lot.GetID() != "AIR-P1" || lot.GetName() != "Airport P1" {
+		t.Errorf("Unexpected identity %q %q", lot.GetID(), lot.GetName())
+	}
+
+	unnamed := domain.NewParkingLot(5)
+	if unnamed.GetName() != unnamed.GetID() {
+		t.Errorf("Expected an unnamed lot to be shown by its ID, got %q", unnamed.GetName())
+	}
+
+	restored, err := domain.RestoreSnapshot(lot.Snapshot())
+	if err != nil {
+		t.Fatalf("Expected restore to succeed, got %v", err)
+	}
+	if restored.GetID() != "AIR-P1" || restored.GetName() != "Airport P1" {
+		t.Errorf("Expected identity to survive a snapshot, got %q %q", restored.GetID(), restored.GetName())
+	}
+}
+
+func TestLotRegistry_ShouldLookLotsUpByID(t *testing.T) {
+	north := domain.NewParkingLot(5, domain.WithID("NORTH"))
+	south := domain.NewParkingLot(5, domain.WithID("SOUTH"))
+	registry := domain.NewLotRegistry()
+
+	if err := registry.Register(north, south); err != nil {
+		t.Fatalf("Expected registration to succeed, got %v", err)
+	}
+	if lot, err := registry.Get("SOUTH"); err != nil || lot != south {
+		t.Errorf("Expected SOUTH, got %v, %v", lot, err)
+	}
+	if _, err := registry.Get("EAST"); !errors.Is(err, domain.ErrUnknownLot) {
+		t.Errorf("Expected ErrUnknownLot, got %v", err)
+	}
+
+	clash := domain.NewParkingLot(5, domain.WithID("NORTH"))
+	if err := registry.Register(clash); !errors.Is(err, domain.ErrDuplicateLotID) {
+		t.Errorf("Expected ErrDuplicateLotID, got %v", err)
+	}
+	if err := registry.Unregister("NORTH"); err != nil {
+		t.Fatalf("Expected unregister to succeed, got %v", err)
+	}
+	if lots := registry.GetLots(); len(lots) != 1 || lots[0] != south {
+		t.Errorf("Expected only SOUTH to remain, got %d lots", len(lots))
+	}
+}
+
+func TestPoliceDepartment_ShouldReportTheSameLotIDWhateverTheSliceOrder(t *testing.T) {
+	north := domain.NewParkingLot(5, domain.WithID("NORTH"), domain.WithName("North Lot"))
+	south := domain.NewParkingLot(5, domain.WithID("SOUTH"))
+	south.Park(domain.Car{Plate: "KA01AB1234", Make: "BMW", Color: "White"})
+	police := domain.NewPoliceDepartment("City Police")
+
+	for _, lots := range [][]*domain.ParkingLot{{north, south}, {south, north}, {south}} {
+		found := police.InvestigateBMWCars(lots)
+		if len(found) != 1 || found[0].LotID != "SOUTH" || found[0].LotName != "SOUTH" {
+			t.Errorf("Expected the BMW in SOUTH, got %+v", found)
+		}
+	}
+	north.Park(domain.Car{Plate: "KA01AB5678", Make: "Toyota", Color: "White"})
+	white := police.InvestigateWhiteCars([]*domain.ParkingLot{south, north})
+	if len(white) != 2 || white[1].LotID != "NORTH" || white[1].LotName != "North Lot" {
+		t.Errorf("Expected the Toyota in North Lot, got %+v", white)
+	}
+}
+
+func TestPoliceDepartment_InvestigateFraudulentPlates_ShouldNameTheLot(t *testing.T) {
+	lot := domain.NewParkingLot(5, domain.WithID("NORTH"), domain.WithName("North Lot"),
+		domain.WithPlateValidation(domain.IndiaPlates, domain.RejectInvalidPlates))
+	lot.Park(domain.Car{Plate: "KA01AB1234"})
+	lot.Park(domain.Car{Plate: "FAKE 123"})
+
+	report := domain.NewPoliceDepartment("City Police").InvestigateFraudulentPlates(lot)
+	if len(report) != 2 {
+		t.Fatalf("Expected the parked and the rejected car, got %+v", report)
+	}
+	for _, investigation := range report {
+		if investigation.LotID != "NORTH" || investigation.LotName != "North Lot" {
+			t.Errorf("Expected every car reported in North Lot, got %+v", investigation)
+		}
+	}
+}
+
+func TestParkingAttendant_LocateCar_ShouldNameTheLot(t *testing.T) {
+	north := domain.NewParkingLot(5, domain.WithID("NORTH"), domain.WithName("North Lot"))
+	south := domain.NewParkingLot(5, domain.WithID("SOUTH"))
+	attendant := domain.NewParkingAttendant("John Doe")
+	attendant.ParkCar(north, domain.Car{Plate: "KA01AB1234"})
+
+	location, err := attendant.LocateCar([]*domain.ParkingLot{south, north}, "KA01AB1234")
+	if err != nil || location.LotID != "NORTH" || location.String() != "North Lot, Slot 0" {
+		t.Errorf("Unexpected location %+v, %v", location, err)
+	}
+	if _, err := attendant.LocateCar([]*domain.ParkingLot{south}, "KA01AB1234"); !errors.Is(err, domain.ErrCarNotFound) {
+		t.Errorf("Expected ErrCarNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,83 @@
+package unit
+
+import (
+	"errors"
+	"parking-lot-system/internal/domain"
+	"testing"
+)
+
+func TestNormalizeCar_ShouldUseCanonicalVocabulary(t *testing.T) {
+	tests := []struct {
+		entered domain.Car
+		want    domain.Car
+	}{
+		{domain.Car{Plate: "mh-12 ab 1234", Make: "TOYOTA", Color: "white"}, domain.Car{Plate: "MH12AB1234", Make: "Toyota", Color: "White"}},
+		{domain.Car{Plate: "KA01XY9999", Make: "bmw", Color: "Grey"}, domain.Car{Plate: "KA01XY9999", Make: "BMW", Color: "Silver"}},
+		{domain.Car{Plate: "DL 3C 0001", Make: "vw", Color: "GRAY"}, domain.Car{Plate: "DL3C0001", Make: "Volkswagen", Color: "Silver"}},
+		{domain.Car{Plate: "X", Make: "mercedes  benz", Color: "navy blue"}, domain.Car{Plate: "X", Make: "Mercedes-Benz", Color: "Blue"}},
+		{domain.Car{Plate: "Y", Make: "rivian", Color: "TEAL"}, domain.Car{Plate: "Y", Make: "Rivian", Color: "Teal"}},
+	}
+
+	for _, test := range tests {
+		if got := domain.NormalizeCar(test.entered); got != test.want {
+			t.Errorf("NormalizeCar(%+v) = %+v, want %+v", test.entered, got, test.want)
+		}
+	}
+}
+
+func TestParkingLot_Finders_ShouldMatchAnySpelling(t *testing.T) {
+	lot := domain.NewParkingLot(5)
+	lot.Park(domain.Car{Plate: "mh-12-ab-1234", Make: "TOYOTA", Color: "blue"})
+	lot.Park(domain.Car{Plate: "MH12AB5678", Make: "Honda", Color: "grey"})
+
+	if len(lot.FindCarsByColor("Blue")) != 1 || len(lot.FindCarsByMakeAndColor("toyota", "BLUE")) != 1 {
+		t.Errorf("Expected the blue Toyota to be found regardless of case")
+	}
+	if cars := lot.FindCarsByColor("Silver"); len(cars) != 1 || cars[0].Plate != "MH12AB5678" {
+		t.Errorf("Expected grey to be found as Silver, got %v", cars)
+	}
+	if lot.FindCar("MH12AB1234") != 0 || lot.FindCar("mh12 ab 1234") != 0 {
+		t.Errorf("Expected plate lookups to ignore spacing and case")
+	}
+
+	police := domain.NewPoliceDepartment("City Police")
+	attendant := domain.NewParkingAttendant("John Doe")
+	if found := police.InvestigateBlueToyotas([]*domain.ParkingLot{lot}, attendant); len(found) != 1 {
+		t.Errorf("Expected InvestigateBlueToyotas to find the TOYOTA, got %d", len(found))
+	}
+}
+
+func TestParkingLot_Park_ShouldKeepEnteredCarForDisplay(t *testing.T) {
+	lot := domain.NewParkingLot(5)
+	entered := domain.Car{Plate: "mh-12 ab 1234", Make: "TOYOTA", Color: "Grey"}
+	lot.Park(entered)
+
+	results := lot.Query(domain.NewQuery().Where(domain.PlateMatches("MH12AB1234")))
+	if len(results) != 1 {
+		t.Fatalf("Expected the car to be found, got %d results", len(results))
+	}
+	if results[0].EnteredAs != entered || results[0].Car.Plate != "MH12AB1234" || results[0].Car.Color != "Silver" {
+		t.Errorf("Unexpected car details: %+v", results[0])
+	}
+
+	restored, err := domain.RestoreSnapshot(lot.Snapshot())
+	if err != nil {
+		t.Fatalf("Expected restore to succeed, got %v", err)
+	}
+	if again := restored.Query(domain.NewQuery()); len(again) != 1 || again[0].EnteredAs != entered {
+		t.Errorf("Expected entered car to survive a snapshot, got %+v", again)
+	}
+}
+
+func TestParkingLot_Park_ShouldRejectSamePlateWrittenDifferently(t *testing.T) {
+	lot := domain.NewParkingLot(5)
+	lot.Park(domain.Car{Plate: "MH12AB1234"})
+
+	err := lot.TryPark(domain.Car{Plate: "mh 12-ab 1234"})
+	if !errors.Is(err, domain.ErrCarAlreadyParked) {
+		t.Errorf("Expected ErrCarAlreadyParked, got %v", err)
+	}
+	if !lot.Unpark(domain.Car{Plate: "MH-12-AB-1234"}) {
+		t.Errorf("Expected unpark to accept a differently written plate")
+	}
+}
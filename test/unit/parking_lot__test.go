@@ -325,7 +325,8 @@ func TestParkingLot_GetParkingTime_ShouldReturnZeroTime_WhenCarNotParked(t *test
 }
 
 func TestParkingLot_GetParkingDuration_ShouldReturnDuration_WhenCarIsParked(t *testing.T) {
-    lot := domain.NewParkingLot(100)
+    clock := domain.NewFakeClock(time.Date(2025, 7, 9, 10, 0, 0, 0, time.UTC))
+    lot := domain.NewParkingLot(100, domain.WithClock(clock))
     car := domain.Car{
         Plate: "MH12AB1234",
         Make:  "Toyota",
@@ -333,12 +334,12 @@ func TestParkingLot_GetParkingDuration_ShouldReturnDuration_WhenCarIsParked(t *t
     }
     
     lot.Park(car)
-    time.Sleep(10 * time.Millisecond) // Small delay to ensure duration > 0
+    clock.Advance(10 * time.Minute)
     
     duration := lot.GetParkingDuration(car.Plate)
     
-    if duration <= 0 {
-        t.Errorf("Expected positive duration, got %v", duration)
+    if duration != 10*time.Minute {
+        t.Errorf("Expected 10m duration, got %v", duration)
     }
 }
 
@@ -700,8 +701,8 @@ func TestPoliceDepartment_InvestigateWhiteCars_ShouldReturnAllWhiteCarsWithLocat
         if location.Car.Color != "White" {
             t.Errorf("Expected white car, got %s", location.Car.Color)
         }
-        if location.LotID < 0 || location.LotID >= len(lots) {
-            t.Errorf("Invalid lot ID: %d", location.LotID)
+        if !isListedLot(lots, location.LotID) {
+            t.Errorf("Invalid lot ID: %s", location.LotID)
         }
         if location.SlotID < 0 {
             t.Errorf("Invalid slot ID: %d", location.SlotID)
@@ -798,8 +799,8 @@ func TestPoliceDepartment_InvestigateBlueToyotas_ShouldReturnCompleteInformation
         if investigation.AttendantName != "Officer Smith" {
             t.Errorf("Expected attendant name 'Officer Smith', got '%s'", investigation.AttendantName)
         }
-        if investigation.LotID < 0 || investigation.LotID >= len(lots) {
-            t.Errorf("Invalid lot ID: %d", investigation.LotID)
+        if !isListedLot(lots, investigation.LotID) {
+            t.Errorf("Invalid lot ID: %s", investigation.LotID)
         }
         if investigation.SlotID < 0 {
             t.Errorf("Invalid slot ID: %d", investigation.SlotID)
@@ -892,8 +893,8 @@ func TestPoliceDepartment_InvestigateBMWCars_ShouldReturnAllBMWsWithLocations(t
         if investigation.Car.Make != "BMW" {
             t.Errorf("Expected BMW car, got %s", investigation.Car.Make)
         }
-        if investigation.LotID < 0 || investigation.LotID >= len(lots) {
-            t.Errorf("Invalid lot ID: %d", investigation.LotID)
+        if !isListedLot(lots, investigation.LotID) {
+            t.Errorf("Invalid lot ID: %s", investigation.LotID)
         }
         if investigation.SlotID < 0 {
             t.Errorf("Invalid slot ID: %d", investigation.SlotID)
@@ -905,7 +906,8 @@ func TestPoliceDepartment_InvestigateBMWCars_ShouldReturnAllBMWsWithLocations(t
 
 //use case-15
 func TestParkingLot_FindCarsParkedInLastMinutes_ShouldReturnRecentCars(t *testing.T) {
-    lot := domain.NewParkingLot(100)
+    clock := domain.NewFakeClock(time.Date(2025, 7, 9, 10, 0, 0, 0, time.UTC))
+    lot := domain.NewParkingLot(100, domain.WithClock(clock))
     
     // Park cars at different times
     oldCar := domain.Car{Plate: "MH12AB1234", Make: "Toyota", Color: "Blue", Size: domain.Small}
@@ -915,9 +917,8 @@ func TestParkingLot_FindCarsParkedInLastMinutes_ShouldReturnRecentCars(t *testin
     // Park old car first
     lot.Park(oldCar)
     
-    // Simulate time passing by manually setting parking time to 45 minutes ago
-    oldTime := time.Now().Add(-45 * time.Minute)
-    lot.SetParkingTime(oldCar.Plate, oldTime)
+    // Simulate 45 minutes passing before the other cars arrive
+    clock.Advance(45 * time.Minute)
     
     // Park recent cars (will have current time)
     lot.Park(recentCar1)
@@ -948,14 +949,14 @@ func TestParkingLot_FindCarsParkedInLastMinutes_ShouldReturnRecentCars(t *testin
 }
 
 func TestParkingLot_FindCarsParkedInLastMinutes_ShouldReturnEmptySlice_WhenNoRecentCars(t *testing.T) {
-    lot := domain.NewParkingLot(100)
+    clock := domain.NewFakeClock(time.Date(2025, 7, 9, 10, 0, 0, 0, time.UTC))
+    lot := domain.NewParkingLot(100, domain.WithClock(clock))
     
     oldCar := domain.Car{Plate: "MH12AB1234", Make: "Toyota", Color: "Blue", Size: domain.Small}
     lot.Park(oldCar)
     
-    // Set parking time to 45 minutes ago
-    oldTime := time.Now().Add(-45 * time.Minute)
-    lot.SetParkingTime(oldCar.Plate, oldTime)
+    // Let 45 minutes pass
+    clock.Advance(45 * time.Minute)
     
     // Find cars parked in last 30 minutes
     recentCars := lot.FindCarsParkedInLastMinutes(30)
@@ -966,8 +967,9 @@ func TestParkingLot_FindCarsParkedInLastMinutes_ShouldReturnEmptySlice_WhenNoRec
 }
 
 func TestPoliceDepartment_InvestigateRecentlyParkedCars_ShouldReturnBombThreatInvestigation(t *testing.T) {
-    lot1 := domain.NewParkingLot(100)
-    lot2 := domain.NewParkingLot(100)
+    clock := domain.NewFakeClock(time.Date(2025, 7, 9, 10, 0, 0, 0, time.UTC))
+    lot1 := domain.NewParkingLot(100, domain.WithClock(clock))
+    lot2 := domain.NewParkingLot(100, domain.WithClock(clock))
     lots := []*domain.ParkingLot{lot1, lot2}
     police := domain.NewPoliceDepartment("City Police")
     
@@ -975,15 +977,12 @@ func TestPoliceDepartment_InvestigateRecentlyParkedCars_ShouldReturnBombThreatIn
     recentCar2 := domain.Car{Plate: "MH12AB9999", Make: "BMW", Color: "Black", Size: domain.Large}
     oldCar := domain.Car{Plate: "MH12AB1234", Make: "Toyota", Color: "Blue", Size: domain.Small}
     
-    // Park cars
-    lot1.Park(recentCar1)
+    // Park the old car 45 minutes before the others
     lot1.Park(oldCar)
+    clock.Advance(45 * time.Minute)
+    lot1.Park(recentCar1)
     lot2.Park(recentCar2)
     
-    // Set old car's parking time to 45 minutes ago
-    oldTime := time.Now().Add(-45 * time.Minute)
-    lot1.SetParkingTime(oldCar.Plate, oldTime)
-    
     bombThreatInvestigation := police.InvestigateRecentlyParkedCars(lots, 30)
     
     if len(bombThreatInvestigation) != 2 {
@@ -992,8 +991,8 @@ func TestPoliceDepartment_InvestigateRecentlyParkedCars_ShouldReturnBombThreatIn
     
     // Verify investigation details
     for _, investigation := range bombThreatInvestigation {
-        if investigation.LotID < 0 || investigation.LotID >= len(lots) {
-            t.Errorf("Invalid lot ID: %d", investigation.LotID)
+        if !isListedLot(lots, investigation.LotID) {
+            t.Errorf("Invalid lot ID: %s", investigation.LotID)
         }
         if investigation.SlotID < 0 {
             t.Errorf("Invalid slot ID: %d", investigation.SlotID)
@@ -1003,7 +1002,7 @@ func TestPoliceDepartment_InvestigateRecentlyParkedCars_ShouldReturnBombThreatIn
         }
         
         // Verify it's actually a recent car
-        timeSinceParking := time.Since(investigation.ParkingTime)
+        timeSinceParking := clock.Now().Sub(investigation.ParkingTime)
         if timeSinceParking > 30*time.Minute {
             t.Errorf("Car should be parked within last 30 minutes, but was parked %v ago", timeSinceParking)
         }
@@ -1107,8 +1106,8 @@ func TestPoliceDepartment_InvestigateHandicapPermitFraud_ShouldReturnCompleteInf
         if !investigation.CarInfo.IsHandicap {
             t.Errorf("Expected handicap car in fraud investigation")
         }
-        if investigation.LotID < 0 || investigation.LotID >= len(lots) {
-            t.Errorf("Invalid lot ID: %d", investigation.LotID)
+        if !isListedLot(lots, investigation.LotID) {
+            t.Errorf("Invalid lot ID: %s", investigation.LotID)
         }
     }
 }
@@ -1192,3 +1191,12 @@ func TestPoliceDepartment_InvestigateFraudulentPlates_ShouldReturnAllCarsInLot(t
     }
 }
 
+// isListedLot reports whether id belongs to one of the lots
+func isListedLot(lots []*domain.ParkingLot, id string) bool {
+    for _, lot := range lots {
+        if lot.GetID() == id {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,102 @@
+package unit
+
+import (
+	"errors"
+	"parking-lot-system/internal/domain"
+	"testing"
+	"time"
+)
+
+func permitTestLot(clock domain.Clock, mode domain.PermitEnforcementMode) (*domain.ParkingLot, *domain.PermitRegistry) {
+	permits := domain.NewPermitRegistry(
+		domain.Permit{Plate: "MH12AB0001", Holder: "A. Rao", ExpiresAt: clock.Now().Add(24 * time.Hour)},
+		domain.Permit{Plate: "MH12AB0002", Holder: "B. Shah", ExpiresAt: clock.Now().Add(-time.Hour)},
+	)
+	lot := domain.NewParkingLot(5,
+		domain.WithClock(clock),
+		domain.WithAccessibleSlots(0, 1),
+		domain.WithPermits(permits, mode),
+	)
+	return lot, permits
+}
+
+func TestPermitRegistry_Verify_ShouldCheckExpiry(t *testing.T) {
+	clock := domain.NewFakeClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	_, permits := permitTestLot(clock, domain.RejectUnpermitted)
+
+	if err := permits.Verify("mh-12-ab-0001", clock.Now()); err != nil {
+		t.Errorf("Expected a valid permit, got %v", err)
+	}
+	if err := permits.Verify("MH12AB0002", clock.Now()); !errors.Is(err, domain.ErrPermitExpired) || !errors.Is(err, domain.ErrNoPermit) {
+		t.Errorf("Expected ErrPermitExpired wrapping ErrNoPermit, got %v", err)
+	}
+	permits.Revoke("MH12AB0001")
+	if err := permits.Verify("MH12AB0001", clock.Now()); !errors.Is(err, domain.ErrNoPermit) {
+		t.Errorf("Expected ErrNoPermit after revoking, got %v", err)
+	}
+}
+
+func TestParkingLot_Park_ShouldPlacePermittedCarsInAccessibleSlots(t *testing.T) {
+	clock := domain.NewFakeClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	lot, _ := permitTestLot(clock, domain.RejectUnpermitted)
+
+	lot.Park(domain.Car{Plate: "KA01XY0001"})
+	if lot.FindCar("KA01XY0001") != 2 {
+		t.Errorf("Expected an ordinary car to skip accessible slots, got slot %d", lot.FindCar("KA01XY0001"))
+	}
+	lot.Park(domain.Car{Plate: "MH12AB0001"})
+	if lot.FindCar("MH12AB0001") != 0 {
+		t.Errorf("Expected the permitted car in an accessible slot, got slot %d", lot.FindCar("MH12AB0001"))
+	}
+	if found := lot.Query(domain.NewQuery().Where(domain.HandicapIs(true))); len(found) != 1 {
+		t.Errorf("Expected the permitted car to be recorded as a handicap car, got %d", len(found))
+	}
+	if lot.GetAvailableAccessibleSpaces() != 1 || lot.GetAvailableSpacesFor(domain.Small) != 2 {
+		t.Errorf("Expected 1 accessible and 2 other free slots, got %d and %d",
+			lot.GetAvailableAccessibleSpaces(), lot.GetAvailableSpacesFor(domain.Small))
+	}
+}
+
+func TestParkingLot_ParkInRow_ShouldRejectUnpermittedHandicapClaims(t *testing.T) {
+	clock := domain.NewFakeClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	lot, _ := permitTestLot(clock, domain.RejectUnpermitted)
+
+	err := lot.TryParkInRow(domain.Car{Plate: "MH12AB0002"}, "A", true)
+	if !errors.Is(err, domain.ErrPermitExpired) {
+		t.Errorf("Expected ErrPermitExpired, got %v", err)
+	}
+	if lot.GetParkedCarsCount() != 0 {
+		t.Errorf("Expected the car not to be parked")
+	}
+
+	police := domain.NewPoliceDepartment("City Police")
+	report := police.InvestigateHandicapPermitFraud([]*domain.ParkingLot{lot}, nil)
+	if len(report) != 1 || !report[0].Rejected || report[0].CarInfo.SlotID != -1 || report[0].Reason != "handicap permit has expired" {
+		t.Errorf("Expected the rejected claim in the report, got %+v", report)
+	}
+}
+
+func TestParkingAttendant_ParkHandicapCar_ShouldFlagUnpermittedCars(t *testing.T) {
+	clock := domain.NewFakeClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	plain := domain.NewParkingLot(5)
+	lot, _ := permitTestLot(clock, domain.FlagUnpermitted)
+	attendant := domain.NewParkingAttendant("John Doe")
+
+	if err := attendant.TryParkHandicapCar([]*domain.ParkingLot{plain, lot}, domain.Car{Plate: "KA01XY9999"}); err != nil {
+		t.Fatalf("Expected a flagged car to park, got %v", err)
+	}
+	if lot.FindCar("KA01XY9999") != 0 {
+		t.Errorf("Expected the car in the lot with accessible slots, got slot %d", lot.FindCar("KA01XY9999"))
+	}
+
+	police := domain.NewPoliceDepartment("City Police")
+	report := police.InvestigateHandicapPermitFraud([]*domain.ParkingLot{plain, lot}, nil)
+	if len(report) != 1 || report[0].Rejected || report[0].Reason != "no handicap permit" || report[0].Location.SlotID != 0 {
+		t.Errorf("Expected the flagged car in the report, got %+v", report)
+	}
+
+	restored, _ := domain.RestoreSnapshot(lot.Snapshot(), domain.WithAccessibleSlots(0, 1))
+	if flagged := restored.Query(domain.NewQuery().Where(domain.PermitFlagged())); len(flagged) != 1 {
+		t.Errorf("Expected the flag to survive a snapshot, got %d", len(flagged))
+	}
+}
@@ -0,0 +1,109 @@
+package unit
+
+import (
+	"errors"
+	"parking-lot-system/internal/domain"
+	"testing"
+)
+
+// MockDuplicateSecurity records duplicate-plate security events
+type MockDuplicateSecurity struct {
+	MockSecurity
+	Conflicts []domain.PlateConflict
+}
+
+func (m *MockDuplicateSecurity) OnDuplicatePlate(conflict domain.PlateConflict) {
+	m.Conflicts = append(m.Conflicts, conflict)
+}
+
+func TestPlateRegistry_ShouldRejectPlateParkedInAnotherLot(t *testing.T) {
+	lot1 := domain.NewParkingLot(10)
+	lot2 := domain.NewParkingLot(10)
+	domain.NewPlateRegistry(lot1, lot2)
+	attendant := domain.NewParkingAttendant("John Doe")
+
+	original := domain.Car{Plate: "MH12AB1234", Make: "Toyota", Color: "Blue"}
+	clone := domain.Car{Plate: "MH12AB1234", Make: "Honda", Color: "White"}
+
+	if !attendant.ParkCar(lot1, original) {
+		t.Fatalf("Expected original car to be parked")
+	}
+	err := attendant.TryParkCar(lot2, clone)
+	if !errors.Is(err, domain.ErrCarAlreadyParked) {
+		t.Errorf("Expected ErrCarAlreadyParked, got %v", err)
+	}
+	if lot2.GetParkedCarsCount() != 0 {
+		t.Errorf("Expected clone not to be parked")
+	}
+}
+
+func TestPlateRegistry_ShouldAllowPlateAgain_AfterUnpark(t *testing.T) {
+	lot1 := domain.NewParkingLot(10)
+	lot2 := domain.NewParkingLot(10)
+	registry := domain.NewPlateRegistry(lot1, lot2)
+	car := domain.Car{Plate: "MH12AB1234", Make: "Toyota", Color: "Blue"}
+
+	lot1.Park(car)
+	lot1.Unpark(car)
+
+	if !lot2.Park(car) {
+		t.Errorf("Expected car to park in lot2 after leaving lot1")
+	}
+	if !registry.IsParked(car.Plate) {
+		t.Errorf("Expected registry to track the plate")
+	}
+}
+
+func TestPlateRegistry_ShouldNotifySecurity_WhenDuplicateDetected(t *testing.T) {
+	lot1 := domain.NewParkingLot(10)
+	lot2 := domain.NewParkingLot(1)
+	domain.NewPlateRegistry(lot1, lot2)
+	security := &MockDuplicateSecurity{}
+	lot2.AddSecurityObserver(security)
+
+	lot1.Park(domain.Car{Plate: "MH12AB1234", Make: "Toyota", Color: "Blue"})
+	lot2.Park(domain.Car{Plate: "MH12AB5678", Make: "BMW", Color: "Black"})
+
+	// lot2 is full, but the clone is still flagged
+	err := lot2.TryPark(domain.Car{Plate: "MH12AB1234", Make: "Honda", Color: "White"})
+	var duplicate *domain.DuplicatePlateError
+	if !errors.As(err, &duplicate) {
+		t.Fatalf("Expected DuplicatePlateError, got %v", err)
+	}
+	if len(security.Conflicts) != 1 {
+		t.Fatalf("Expected 1 security event, got %d", len(security.Conflicts))
+	}
+	if security.Conflicts[0].ParkedLot != lot1 || security.Conflicts[0].RejectedCar.Make != "Honda" {
+		t.Errorf("Unexpected conflict details: %+v", security.Conflicts[0])
+	}
+}
+
+func TestPoliceDepartment_InvestigateClonedPlates_ShouldReportBothCars(t *testing.T) {
+	lot1 := domain.NewParkingLot(10)
+	lot2 := domain.NewParkingLot(10)
+	registry := domain.NewPlateRegistry(lot1, lot2)
+	police := domain.NewPoliceDepartment("City Police")
+
+	lot1.Park(domain.Car{Plate: "MH12AB0001", Make: "BMW", Color: "Black"})
+	lot1.Park(domain.Car{Plate: "MH12AB1234", Make: "Toyota", Color: "Blue"})
+	lot2.Park(domain.Car{Plate: "MH12AB1234", Make: "Honda", Color: "White"})
+
+	clones := police.InvestigateClonedPlates(registry)
+	if len(clones) != 1 {
+		t.Fatalf("Expected 1 cloned plate, got %d", len(clones))
+	}
+	clone := clones[0]
+	if clone.ParkedLotID != lot1.GetID() || clone.ParkedSlotID != 1 || clone.AttemptedLotID != lot2.GetID() {
+		t.Errorf("Unexpected locations: %+v", clone)
+	}
+	if clone.SuspectCar.Make != "Honda" {
+		t.Errorf("Expected suspect Honda, got %s", clone.SuspectCar.Make)
+	}
+
+	plates := police.InvestigateFraudulentPlates(lot1)
+	for _, investigation := range plates {
+		if investigation.Car.Plate == "MH12AB1234" && len(investigation.CloneAttempts) != 1 {
+			t.Errorf("Expected clone attempt on fraudulent plate report")
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package unit
+
+import (
+	"errors"
+	"parking-lot-system/internal/domain"
+	"testing"
+)
+
+func TestPlateRules_ShouldValidateRegionalFormats(t *testing.T) {
+	tests := []struct {
+		rule  domain.PlateRule
+		plate string
+		valid bool
+	}{
+		{domain.IndiaPlates, "MH12AB1234", true},
+		{domain.IndiaPlates, "22BH1234AA", true},
+		{domain.IndiaPlates, "MH12AB12345", false},
+		{domain.UKPlates, "AB12CDE", true},
+		{domain.UKPlates, "AB1CDE", false},
+		{domain.CaliforniaPlates, "7ABC123", true},
+		{domain.SingaporePlates, "SBS3229P", true},
+		{domain.SingaporePlates, "SBS3229A", false},
+		{domain.SingaporePlates, "S1234", false},
+	}
+
+	for _, test := range tests {
+		err := test.rule.Validate(test.plate)
+		if (err == nil) != test.valid {
+			t.Errorf("%s %s: expected valid=%v, got %v", test.rule.Region, test.plate, test.valid, err)
+		}
+	}
+}
+
+func TestPlateRuleSet_ShouldExplainChecksumFailures(t *testing.T) {
+	rules := domain.PlateRuleSet{domain.IndiaPlates, domain.SingaporePlates}
+
+	if err := rules.Validate("SBS3229P"); err != nil {
+		t.Errorf("Expected Singapore plate to be accepted, got %v", err)
+	}
+
+	var invalid *domain.PlateValidationError
+	if err := rules.Validate("SBS3229A"); !errors.As(err, &invalid) || invalid.Reason != "Singapore plate has check letter A, expected P" {
+		t.Errorf("Expected checksum reason, got %v", err)
+	}
+	if err := rules.Validate("HELLO"); !errors.As(err, &invalid) || invalid.Reason != "does not match any accepted format (India, Singapore)" {
+		t.Errorf("Expected format reason, got %v", err)
+	}
+}
+
+func TestParkingLot_Park_ShouldRejectInvalidPlates(t *testing.T) {
+	lot := domain.NewParkingLot(5, domain.WithPlateValidation(domain.IndiaPlates, domain.RejectInvalidPlates))
+
+	if err := lot.TryPark(domain.Car{Plate: "mh-12-ab-1234"}); err != nil {
+		t.Fatalf("Expected valid plate to park, got %v", err)
+	}
+	err := lot.TryPark(domain.Car{Plate: "FAKE 123"})
+	if !errors.Is(err, domain.ErrInvalidPlate) || !errors.Is(err, domain.ErrInvalidCar) {
+		t.Errorf("Expected ErrInvalidPlate wrapping ErrInvalidCar, got %v", err)
+	}
+	if lot.GetParkedCarsCount() != 1 {
+		t.Errorf("Expected rejected car not to be parked")
+	}
+
+	police := domain.NewPoliceDepartment("City Police")
+	report := police.InvestigateFraudulentPlates(lot)
+	if len(report) != 2 || !report[1].Rejected || report[1].SlotID != -1 || report[1].Car.Plate != "FAKE 123" {
+		t.Fatalf("Expected the rejected car at the end of the report, got %+v", report)
+	}
+	if len(report[1].SuspectReasons) != 1 || report[1].SuspectReasons[0] != "does not match the India plate format" {
+		t.Errorf("Unexpected reasons: %v", report[1].SuspectReasons)
+	}
+	if len(report[0].SuspectReasons) != 0 {
+		t.Errorf("Expected no suspicion on a valid plate, got %v", report[0].SuspectReasons)
+	}
+}
+
+func TestParkingLot_ParkVehicle_ShouldNotValidateBicycleRackTags(t *testing.T) {
+	lot := domain.NewParkingLot(5, domain.WithPlateValidation(domain.IndiaPlates, domain.RejectInvalidPlates))
+
+	if _, err := lot.ParkVehicle(domain.Bicycle{Tag: "RACK-17"}); err != nil {
+		t.Fatalf("Expected the bicycle to park, got %v", err)
+	}
+	police := domain.NewPoliceDepartment("City Police")
+	if report := police.InvestigateFraudulentPlates(lot); len(report) != 1 || report[0].Rejected || len(report[0].SuspectReasons) != 0 {
+		t.Errorf("Expected no suspicion on a rack tag, got %+v", report)
+	}
+}
+
+func TestParkingLot_Park_ShouldFlagInvalidPlates(t *testing.T) {
+	lot := domain.NewParkingLot(5, domain.WithPlateValidation(domain.SingaporePlates, domain.FlagInvalidPlates))
+
+	if err := lot.TryPark(domain.Car{Plate: "SBS 3229 A"}); err != nil {
+		t.Fatalf("Expected flagged plate to park, got %v", err)
+	}
+
+	police := domain.NewPoliceDepartment("City Police")
+	report := police.InvestigateFraudulentPlates(lot)
+	if len(report) != 1 || report[0].Rejected || report[0].SlotID != 0 {
+		t.Fatalf("Expected the parked car in the report, got %+v", report)
+	}
+	if len(report[0].SuspectReasons) != 1 || report[0].SuspectReasons[0] != "Singapore plate has check letter A, expected P" {
+		t.Errorf("Unexpected reasons: %v", report[0].SuspectReasons)
+	}
+
+	restored, _ := domain.RestoreSnapshot(lot.Snapshot())
+	if flagged := police.InvestigateFraudulentPlates(restored); len(flagged) != 1 || len(flagged[0].SuspectReasons) != 1 {
+		t.Errorf("Expected the flag to survive a snapshot, got %+v", flagged)
+	}
+}
@@ -0,0 +1,112 @@
+package unit
+
+import (
+	"errors"
+	"parking-lot-system/internal/domain"
+	"testing"
+	"time"
+)
+
+func TestPoliceDepartment_Investigate_ShouldRunTextQueryAcrossLots(t *testing.T) {
+	clock := domain.NewFakeClock(time.Date(2025, 7, 9, 10, 0, 0, 0, time.UTC))
+	lot1 := domain.NewParkingLot(5, domain.WithClock(clock))
+	lot2 := domain.NewParkingLot(5, domain.WithClock(clock))
+	attendant := domain.NewParkingAttendant("John Doe")
+	police := domain.NewPoliceDepartment("City Police")
+
+	// Parked an hour ago, outside the 30 minute window
+	lot1.ParkInRow(domain.Car{Plate: "MH12AB0001", Make: "Toyota", Color: "White"}, "B", false)
+	clock.Advance(time.Hour)
+
+	attendant.ParkCar(lot1, domain.Car{Plate: "MH12AB0002", Make: "Toyota", Color: "White"})
+	lot2.ParkInRow(domain.Car{Plate: "MH12AB0003", Make: "BMW", Color: "White"}, "D", false)
+	lot2.ParkInRow(domain.Car{Plate: "MH12AB0004", Make: "Honda", Color: "White"}, "D", false)
+	lot2.ParkInRow(domain.Car{Plate: "MH12AB0005", Make: "BMW", Color: "Black"}, "B", false)
+	lot1.ParkInRow(domain.Car{Plate: "MH12AB0006", Make: "Toyota", Color: "White"}, "C", false)
+
+	results, err := police.Investigate([]*domain.ParkingLot{lot1, lot2},
+		"color=White AND make IN (Toyota,BMW) AND parked_within=30m AND row IN (B,D)")
+	if err != nil {
+		t.Fatalf("Expected query to parse, got %v", err)
+	}
+	if len(results) != 1 || results[0].Car.Plate != "MH12AB0003" {
+		t.Fatalf("Expected only MH12AB0003, got %+v", results)
+	}
+	result := results[0]
+	if result.LotID != lot2.GetID() || result.SlotID != 0 || result.Row != "D" || !result.EntryTime.Equal(clock.Now()) {
+		t.Errorf("Unexpected investigation result: %+v", result)
+	}
+
+	byAttendant, _ := police.Investigate([]*domain.ParkingLot{lot1, lot2}, `attendant="John Doe"`)
+	if len(byAttendant) != 1 || byAttendant[0].Car.Plate != "MH12AB0002" || byAttendant[0].AttendantName != "John Doe" {
+		t.Errorf("Expected the car parked by John Doe, got %+v", byAttendant)
+	}
+}
+
+func TestParseQuery_ShouldHonourPrecedenceAndNegation(t *testing.T) {
+	lot, _ := queryTestLot(t)
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"make=BMW OR make=Honda AND color=Red", []string{"MH12AB0002", "KA01XY0004", "MH12AB0005"}},
+		{"(make=BMW OR make=Toyota) AND color=White", []string{"MH12AB0001", "MH12AB0002"}},
+		{"not row in (A, B) and handicap=false", []string{"MH12AB0005"}},
+		{"plate=KA01* AND size!=small", []string{"KA01XY0003"}},
+		{"SIZE IN (Large, medium) AND parked_longer_than=1m", []string{"MH12AB0002", "KA01XY0003"}},
+	}
+
+	for _, test := range tests {
+		query, err := domain.ParseQuery(test.query)
+		if err != nil {
+			t.Errorf("%q: expected query to parse, got %v", test.query, err)
+			continue
+		}
+		got := platesOf(lot.Query(query))
+		if len(got) != len(test.want) {
+			t.Errorf("%q: expected %v, got %v", test.query, test.want, got)
+			continue
+		}
+		for i := range got {
+			if got[i] != test.want[i] {
+				t.Errorf("%q: expected %v, got %v", test.query, test.want, got)
+				break
+			}
+		}
+	}
+}
+
+func TestParseQuery_ShouldReportErrorPositions(t *testing.T) {
+	tests := []struct {
+		query string
+		pos   int
+	}{
+		{"", 0},
+		{"colour=White", 0},
+		{"color=White AND", 15},
+		{"color=White AND make IN Toyota", 24},
+		{"color=White make=BMW", 12},
+		{"parked_within=soon", 14},
+		{"size=Huge", 5},
+		{"handicap IN (true)", 9},
+		{"(color=White", 12},
+		{`make="Toyota`, 5},
+		{"color!White", 5},
+	}
+
+	for _, test := range tests {
+		_, err := domain.ParseQuery(test.query)
+		var syntaxErr *domain.QuerySyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("%q: expected a QuerySyntaxError, got %v", test.query, err)
+			continue
+		}
+		if syntaxErr.Pos != test.pos {
+			t.Errorf("%q: expected error at %d, got %d (%v)", test.query, test.pos, syntaxErr.Pos, err)
+		}
+		if !errors.Is(err, domain.ErrInvalidQuery) {
+			t.Errorf("%q: expected ErrInvalidQuery, got %v", test.query, err)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package unit
+
+import (
+	"parking-lot-system/internal/domain"
+	"testing"
+	"time"
+)
+
+// queryTestLot parks five cars one minute apart
+func queryTestLot(t *testing.T) (*domain.ParkingLot, *domain.FakeClock) {
+	t.Helper()
+	clock := domain.NewFakeClock(time.Date(2025, 7, 9, 10, 0, 0, 0, time.UTC))
+	lot := domain.NewParkingLot(10, domain.WithClock(clock))
+
+	lot.ParkInRow(domain.Car{Plate: "MH12AB0001", Make: "Toyota", Color: "White", Size: domain.Small}, "A", false)
+	clock.Advance(time.Minute)
+	lot.ParkInRow(domain.Car{Plate: "MH12AB0002", Make: "BMW", Color: "White", Size: domain.Large}, "B", true)
+	clock.Advance(time.Minute)
+	lot.ParkInRow(domain.Car{Plate: "KA01XY0003", Make: "Toyota", Color: "Blue", Size: domain.Medium}, "B", false)
+	clock.Advance(time.Minute)
+	lot.ParkInRow(domain.Car{Plate: "KA01XY0004", Make: "Honda", Color: "Red", Size: domain.Small}, "D", true)
+	clock.Advance(time.Minute)
+	lot.ParkInRow(domain.Car{Plate: "MH12AB0005", Make: "BMW", Color: "Black", Size: domain.Large}, "D", false)
+	return lot, clock
+}
+
+func platesOf(results []domain.CarParkingInfo) []string {
+	var plates []string
+	for _, info := range results {
+		plates = append(plates, info.Car.Plate)
+	}
+	return plates
+}
+
+func assertPlates(t *testing.T, got []domain.CarParkingInfo, want ...string) {
+	t.Helper()
+	plates := platesOf(got)
+	if len(plates) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, plates)
+	}
+	for i := range want {
+		if plates[i] != want[i] {
+			t.Fatalf("Expected %v, got %v", want, plates)
+		}
+	}
+}
+
+func TestParkingLot_Query_ShouldCombineAndOrNot(t *testing.T) {
+	lot, _ := queryTestLot(t)
+
+	results := lot.Query(domain.NewQuery().
+		Where(domain.PlateMatches("MH12*")).
+		Where(domain.Or(domain.MakeIs("BMW"), domain.ColorIs("White"))).
+		Where(domain.Not(domain.RowIn("D"))))
+
+	assertPlates(t, results, "MH12AB0001", "MH12AB0002")
+}
+
+func TestParkingLot_Query_ShouldFilterBySizeRowAndHandicap(t *testing.T) {
+	lot, _ := queryTestLot(t)
+
+	results := lot.Query(domain.NewQuery().
+		Where(domain.HandicapIs(true)).
+		Where(domain.RowIn("B", "D")).
+		Where(domain.Not(domain.SizeIs(domain.Large))))
+
+	assertPlates(t, results, "KA01XY0004")
+}
+
+func TestParkingLot_Query_ShouldFilterByEntryTimeAndDuration(t *testing.T) {
+	lot, clock := queryTestLot(t)
+	start := time.Date(2025, 7, 9, 10, 0, 0, 0, time.UTC)
+
+	between := lot.Query(domain.NewQuery().Where(domain.ParkedBetween(start.Add(time.Minute), start.Add(3*time.Minute))))
+	assertPlates(t, between, "MH12AB0002", "KA01XY0003", "KA01XY0004")
+
+	clock.Advance(time.Minute)
+	recent := lot.Query(domain.NewQuery().Where(domain.ParkedWithin(150 * time.Second)))
+	assertPlates(t, recent, "KA01XY0004", "MH12AB0005")
+
+	long := lot.Query(domain.NewQuery().Where(domain.ParkedLongerThan(3 * time.Minute)))
+	assertPlates(t, long, "MH12AB0001", "MH12AB0002")
+}
+
+func TestParkingLot_Query_ShouldSortAndLimit(t *testing.T) {
+	lot, _ := queryTestLot(t)
+	lot.Unpark(domain.Car{Plate: "MH12AB0001"})
+	lot.Park(domain.Car{Plate: "AA00AA0000", Make: "Honda", Color: "Red", Size: domain.Small})
+
+	bySlot := lot.Query(domain.NewQuery().Limit(2))
+	assertPlates(t, bySlot, "AA00AA0000", "MH12AB0002")
+
+	byEntry := lot.Query(domain.NewQuery().SortBy(domain.SortByEntryTime, false).Limit(2))
+	assertPlates(t, byEntry, "MH12AB0002", "KA01XY0003")
+
+	newestFirst := lot.Query(domain.NewQuery().SortBy(domain.SortByEntryTime, true).Limit(1))
+	assertPlates(t, newestFirst, "AA00AA0000")
+
+	byPlate := lot.Query(domain.NewQuery().Where(domain.ColorIs("Red")).SortBy(domain.SortByPlate, false))
+	assertPlates(t, byPlate, "AA00AA0000", "KA01XY0004")
+
+	longestFirst := lot.Query(domain.NewQuery().SortBy(domain.SortByDuration, true).Limit(1))
+	assertPlates(t, longestFirst, "MH12AB0002")
+}
+
+func TestParkingLot_Query_ShouldReportEntryTime(t *testing.T) {
+	lot, _ := queryTestLot(t)
+
+	results := lot.Query(domain.NewQuery().Where(domain.PlateMatches("KA01XY0003")))
+	if len(results) != 1 || !results[0].ParkedAt.Equal(lot.GetParkingTime("KA01XY0003")) {
+		t.Errorf("Expected entry time on query result, got %+v", results)
+	}
+}
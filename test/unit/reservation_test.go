@@ -0,0 +1,256 @@
+package unit
+
+import (
+	"errors"
+	"parking-lot-system/internal/domain"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func reservationTestLot(clock domain.Clock) *domain.ParkingLot {
+	return domain.NewParkingLot(3,
+		domain.WithClock(clock),
+		domain.WithReservationGrace(15*time.Minute),
+		domain.WithSlotSizes(
+			domain.SlotBlock{Size: domain.SmallSlot, Slots: 2},
+			domain.SlotBlock{Size: domain.LargeSlot, Slots: 1},
+		),
+	)
+}
+
+func TestParkingLot_Reserve_ShouldHoldSlotAndHonourArrival(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	clock := domain.NewFakeClock(start.Add(-time.Hour))
+	lot := reservationTestLot(clock)
+
+	reservation, err := lot.Reserve("KA01AB0001", domain.SmallSlot, start, start.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("Expected the booking to succeed, got %v", err)
+	}
+	if lot.GetAvailableSpaces() != 3 {
+		t.Errorf("Expected no slot held before the window opens, got %d free", lot.GetAvailableSpaces())
+	}
+
+	clock.Set(start)
+	if lot.GetAvailableSpaces() != 2 || lot.GetAvailableSpacesFor(domain.Small) != 2 {
+		t.Errorf("Expected one slot held once the window opens, got %d free", lot.GetAvailableSpaces())
+	}
+	lot.Park(domain.Car{Plate: "KA01XY0001", Size: domain.Small})
+	held, _ := lot.GetReservation(reservation.ID)
+	if lot.FindCar("KA01XY0001") == held.SlotID {
+		t.Errorf("Expected a walk-in car to skip the held slot %d", held.SlotID)
+	}
+
+	clock.Advance(10 * time.Minute)
+	lot.Park(domain.Car{Plate: "ka01 ab 0001", Size: domain.Small})
+	honoured, _ := lot.GetReservation(reservation.ID)
+	if honoured.Status != domain.ReservationHonoured || lot.FindCar("KA01AB0001") != held.SlotID {
+		t.Errorf("Expected the reserved car in slot %d, got %d (%s)", held.SlotID, lot.FindCar("KA01AB0001"), honoured.Status)
+	}
+	if lot.GetAvailableSpaces() != 1 {
+		t.Errorf("Expected 1 free slot, got %d", lot.GetAvailableSpaces())
+	}
+}
+
+func TestParkingLot_Reserve_ShouldReleaseNoShowsAfterGracePeriod(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	clock := domain.NewFakeClock(start)
+	lot := reservationTestLot(clock)
+	reservation, _ := lot.Reserve("KA01AB0001", domain.LargeSlot, start, start.Add(2*time.Hour))
+	lot.Park(domain.Car{Plate: "KA01SM0001", Size: domain.Small})
+	lot.Park(domain.Car{Plate: "KA01SM0002", Size: domain.Small})
+
+	if err := lot.TryPark(domain.Car{Plate: "KA01XY0001", Size: domain.Large}); !errors.Is(err, domain.ErrLotFull) {
+		t.Errorf("Expected the held large slot to turn a large car away, got %v", err)
+	}
+
+	clock.Advance(16 * time.Minute)
+	if err := lot.TryPark(domain.Car{Plate: "KA01XY0001", Size: domain.Large}); err != nil {
+		t.Errorf("Expected the no-show's slot to be released, got %v", err)
+	}
+	if released, _ := lot.GetReservation(reservation.ID); released.Status != domain.ReservationNoShow || released.SlotID != -1 {
+		t.Errorf("Expected a released no-show, got %+v", released)
+	}
+}
+
+func TestParkingLot_Reserve_ShouldKeepBookingThroughEarlyVisit(t *testing.T) {
+	start := time.Date(2024, 1, 8, 10, 0, 0, 0, time.UTC)
+	clock := domain.NewFakeClock(start.Add(-7 * 24 * time.Hour))
+	lot := domain.NewParkingLot(1, domain.WithClock(clock), domain.WithReservationEarlyArrival(10*time.Minute))
+	reservation, _ := lot.Reserve("KA01AB0001", domain.AnySize, start, start.Add(2*time.Hour))
+
+	lot.Park(domain.Car{Plate: "KA01AB0001", Size: domain.Small})
+	clock.Advance(time.Hour)
+	lot.Unpark(domain.Car{Plate: "KA01AB0001"})
+	if booked, _ := lot.GetReservation(reservation.ID); booked.Status != domain.ReservationBooked {
+		t.Errorf("Expected an early visit to keep the booking, got %s", booked.Status)
+	}
+
+	clock.Set(start.Add(-5 * time.Minute))
+	lot.Park(domain.Car{Plate: "KA01AB0001", Size: domain.Small})
+	if honoured, _ := lot.GetReservation(reservation.ID); honoured.Status != domain.ReservationHonoured {
+		t.Errorf("Expected an arrival within the early-arrival margin to honour the booking, got %s", honoured.Status)
+	}
+}
+
+func TestParkingLot_Reserve_ShouldNotOverbookSlotType(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	clock := domain.NewFakeClock(start.Add(-time.Hour))
+	lot := reservationTestLot(clock)
+
+	lot.Reserve("KA01AB0001", domain.SmallSlot, start, start.Add(2*time.Hour))
+	lot.Reserve("KA01AB0002", domain.SmallSlot, start.Add(time.Hour), start.Add(3*time.Hour))
+	if _, err := lot.Reserve("KA01AB0003", domain.SmallSlot, start.Add(90*time.Minute), start.Add(4*time.Hour)); !errors.Is(err, domain.ErrReservationUnavailable) {
+		t.Errorf("Expected ErrReservationUnavailable, got %v", err)
+	}
+	if _, err := lot.Reserve("KA01AB0003", domain.SmallSlot, start.Add(3*time.Hour), start.Add(4*time.Hour)); err != nil {
+		t.Errorf("Expected a later window to be bookable, got %v", err)
+	}
+	if _, err := lot.Reserve("KA01AB0001", domain.LargeSlot, start, start.Add(time.Hour)); !errors.Is(err, domain.ErrAlreadyReserved) {
+		t.Errorf("Expected ErrAlreadyReserved, got %v", err)
+	}
+	if _, err := lot.Reserve("KA01AB0004", domain.SmallSlot, start, start); !errors.Is(err, domain.ErrInvalidReservation) {
+		t.Errorf("Expected ErrInvalidReservation for an empty window, got %v", err)
+	}
+	if _, err := lot.Reserve("KA01AB0004", domain.SmallSlot, start.Add(-50*time.Hour), start.Add(-48*time.Hour)); !errors.Is(err, domain.ErrInvalidReservation) {
+		t.Errorf("Expected ErrInvalidReservation for a window that has ended, got %v", err)
+	}
+	if len(lot.GetReservations()) != 3 {
+		t.Errorf("Expected refused bookings not to be kept, got %d reservations", len(lot.GetReservations()))
+	}
+}
+
+func TestParkingLot_Reserve_ShouldNotifyOwnerOfReservedFullness(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	clock := domain.NewFakeClock(start.Add(-time.Minute))
+	owner := &MockOwner{}
+	lot := domain.NewParkingLot(2, domain.WithClock(clock), domain.WithOwnerObserver(owner))
+	lot.Park(domain.Car{Plate: "KA01XY0001"})
+	reservation, _ := lot.Reserve("KA01AB0001", domain.AnySize, start, start.Add(time.Hour))
+
+	clock.Set(start)
+	lot.ProcessReservations()
+	if !owner.WasNotified || owner.Message != "Lot is full with reserved slots held" || !lot.IsFull() {
+		t.Errorf("Expected the owner to hear the lot filled with reservations, got %+v", owner)
+	}
+
+	if err := lot.CancelReservation(reservation.ID); err != nil {
+		t.Fatalf("Expected the reservation to be cancelled, got %v", err)
+	}
+	if !owner.SpaceNotified || lot.IsFull() {
+		t.Errorf("Expected the owner to hear space came back, got %+v", owner)
+	}
+	if err := lot.CancelReservation(reservation.ID); !errors.Is(err, domain.ErrUnknownReservation) {
+		t.Errorf("Expected a cancelled reservation not to be cancelled again, got %v", err)
+	}
+}
+
+func TestParkingLot_Getters_ShouldReportHoldsWithoutChangingThem(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	clock := domain.NewFakeClock(start.Add(-time.Minute))
+	owner := &MockOwner{}
+	lot := domain.NewParkingLot(2, domain.WithClock(clock), domain.WithOwnerObserver(owner))
+	lot.Park(domain.Car{Plate: "KA01XY0001"})
+	reservation, _ := lot.Reserve("KA01AB0001", domain.AnySize, start, start.Add(time.Hour))
+
+	clock.Set(start)
+	if !lot.IsFull() || lot.GetAvailableSpaces() != 0 || lot.CanFitVehicle(domain.Car{Plate: "KA01XY0002"}) {
+		t.Errorf("Expected the held slot to count as taken, got %d free", lot.GetAvailableSpaces())
+	}
+	if owner.WasNotified {
+		t.Errorf("Expected getters not to notify the owner")
+	}
+	if pending, _ := lot.GetReservation(reservation.ID); pending.SlotID != -1 {
+		t.Errorf("Expected getters not to hold the slot, got %+v", pending)
+	}
+
+	clock.Advance(15 * time.Minute)
+	if lot.IsFull() || lot.GetAvailableSpaces() != 1 || !lot.CanFitVehicle(domain.Car{Plate: "KA01XY0002"}) {
+		t.Errorf("Expected the no-show's slot to count as free, got %d free", lot.GetAvailableSpaces())
+	}
+	lot.ProcessReservations()
+	if released, _ := lot.GetReservation(reservation.ID); released.Status != domain.ReservationNoShow || owner.WasNotified {
+		t.Errorf("Expected ProcessReservations to release the no-show quietly, got %+v and %+v", released, owner)
+	}
+}
+
+func TestRestoreSnapshot_ShouldKeepReservationsAndHolds(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	clock := domain.NewFakeClock(start)
+	sizes := domain.WithSlotSizes(domain.SlotBlock{Size: domain.SmallSlot, Slots: 2}, domain.SlotBlock{Size: domain.LargeSlot, Slots: 1})
+	lot := domain.NewParkingLot(3, domain.WithClock(clock), sizes)
+	reservation, _ := lot.Reserve("KA01AB0001", domain.SmallSlot, start, start.Add(2*time.Hour))
+	lot.ProcessReservations()
+
+	restored, err := domain.RestoreSnapshot(lot.Snapshot(), domain.WithClock(clock), sizes)
+	if err != nil {
+		t.Fatalf("Expected the snapshot to restore, got %v", err)
+	}
+	if !reflect.DeepEqual(restored.GetReservations(), lot.GetReservations()) {
+		t.Errorf("Expected reservations %+v, got %+v", lot.GetReservations(), restored.GetReservations())
+	}
+	if restored.GetAvailableSpaces() != 2 || restored.GetAvailableSpacesBySize()[domain.SmallSlot] != 1 {
+		t.Errorf("Expected the held slot to stay held, got %v free", restored.GetAvailableSpacesBySize())
+	}
+	held, _ := restored.GetReservation(reservation.ID)
+	restored.Park(domain.Car{Plate: "KA01XY0001", Size: domain.Small})
+	if restored.FindCar("KA01XY0001") == held.SlotID {
+		t.Errorf("Expected a walk-in car to skip the held slot %d", held.SlotID)
+	}
+
+	largeSlots := domain.WithSlotSizes(domain.SlotBlock{Size: domain.LargeSlot, Slots: 3})
+	if _, err := domain.RestoreSnapshot(lot.Snapshot(), largeSlots); !errors.Is(err, domain.ErrCorruptSnapshot) {
+		t.Errorf("Expected ErrCorruptSnapshot for a hold on a slot of another size, got %v", err)
+	}
+}
+
+func TestReplayJournal_ShouldRebuildReservations(t *testing.T) {
+	journal, journalPath := openTestJournal(t)
+	snapshotPath := filepath.Join(t.TempDir(), "lot.json")
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	clock := domain.NewFakeClock(start.Add(-time.Hour))
+	sizes := domain.WithSlotSizes(domain.SlotBlock{Size: domain.SmallSlot, Slots: 2}, domain.SlotBlock{Size: domain.LargeSlot, Slots: 1})
+	lot := domain.NewParkingLot(3, domain.WithClock(clock), sizes)
+	lot.AttachJournal(journal)
+
+	honoured, _ := lot.Reserve("KA01AB0001", domain.SmallSlot, start, start.Add(2*time.Hour))
+	lot.Reserve("KA01AB0002", domain.LargeSlot, start, start.Add(2*time.Hour))
+	cancelled, _ := lot.Reserve("KA01AB0003", domain.SmallSlot, start.Add(time.Hour), start.Add(3*time.Hour))
+	clock.Set(start)
+	lot.Park(domain.Car{Plate: "KA01XY0001", Size: domain.Small})
+	lot.SaveSnapshotFile(snapshotPath)
+
+	lot.CancelReservation(cancelled.ID)
+	clock.Advance(10 * time.Minute)
+	lot.Park(domain.Car{Plate: honoured.Plate, Size: domain.Small})
+	clock.Advance(6 * time.Minute)
+	lot.ProcessReservations()
+	lot.Reserve("KA01AB0004", domain.LargeSlot, start.Add(20*time.Minute), start.Add(time.Hour))
+	clock.Advance(4 * time.Minute)
+	lot.ProcessReservations()
+
+	replayed, err := domain.ReplayJournal(journalPath, domain.WithClock(clock), sizes)
+	if err != nil {
+		t.Fatalf("Expected replay to succeed, got %v", err)
+	}
+	recovered, err := domain.RecoverLot(snapshotPath, journalPath, domain.WithClock(clock), sizes)
+	if err != nil {
+		t.Fatalf("Expected recovery to succeed, got %v", err)
+	}
+	statuses := []domain.ReservationStatus{domain.ReservationHonoured, domain.ReservationNoShow, domain.ReservationCancelled, domain.ReservationBooked}
+	for i, reservation := range lot.GetReservations() {
+		if reservation.Status != statuses[i] {
+			t.Errorf("Reservation %d: expected %s, got %s", i, statuses[i], reservation.Status)
+		}
+	}
+	for name, rebuilt := range map[string]*domain.ParkingLot{"replayed": replayed, "recovered": recovered} {
+		if !reflect.DeepEqual(rebuilt.GetReservations(), lot.GetReservations()) {
+			t.Errorf("Expected %s reservations %+v, got %+v", name, lot.GetReservations(), rebuilt.GetReservations())
+		}
+		if !rebuilt.IsFull() || rebuilt.GetAvailableSpaces() != 0 {
+			t.Errorf("Expected the %s lot to be full with its hold, got %d free", name, rebuilt.GetAvailableSpaces())
+		}
+	}
+}
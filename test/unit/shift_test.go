@@ -0,0 +1,105 @@
+package unit
+
+import (
+	"errors"
+	"parking-lot-system/internal/domain"
+	"testing"
+	"time"
+)
+
+func shiftTestSchedule(t *testing.T, start time.Time) *domain.ShiftSchedule {
+	schedule := domain.NewShiftSchedule()
+	shifts := []domain.Shift{
+		{ID: "AM-JOHN", Attendant: "John Doe", LotIDs: []string{"NORTH"}, Start: start, End: start.Add(8 * time.Hour)},
+		{ID: "AM-JANE", Attendant: "Jane Roe", LotIDs: []string{"NORTH", "SOUTH"}, Start: start, End: start.Add(8 * time.Hour)},
+		{ID: "PM-JOHN", Attendant: "John Doe", LotIDs: []string{"SOUTH"}, Start: start.Add(8 * time.Hour), End: start.Add(16 * time.Hour)},
+	}
+	for _, shift := range shifts {
+		if err := schedule.Add(shift); err != nil {
+			t.Fatalf("Expected shift %s to be added, got %v", shift.ID, err)
+		}
+	}
+	return schedule
+}
+
+func TestShiftSchedule_Add_ShouldRejectOverlappingShifts(t *testing.T) {
+	start := time.Date(2024, 1, 1, 6, 0, 0, 0, time.UTC)
+	schedule := shiftTestSchedule(t, start)
+
+	err := schedule.Add(domain.Shift{ID: "EXTRA", Attendant: "Jane Roe", LotIDs: []string{"SOUTH"}, Start: start.Add(7 * time.Hour), End: start.Add(9 * time.Hour)})
+	if !errors.Is(err, domain.ErrShiftOverlap) {
+		t.Errorf("Expected ErrShiftOverlap, got %v", err)
+	}
+	err = schedule.Add(domain.Shift{ID: "BACKWARDS", Attendant: "Max Poe", LotIDs: []string{"SOUTH"}, Start: start, End: start})
+	if !errors.Is(err, domain.ErrInvalidShift) {
+		t.Errorf("Expected ErrInvalidShift, got %v", err)
+	}
+	if onDuty := schedule.OnDuty("NORTH", start.Add(time.Hour)); len(onDuty) != 2 {
+		t.Errorf("Expected two attendants on duty at NORTH, got %v", onDuty)
+	}
+}
+
+func TestParkingLot_WithShiftSchedule_ShouldOnlyLetOnDutyAttendantsWork(t *testing.T) {
+	start := time.Date(2024, 1, 1, 6, 0, 0, 0, time.UTC)
+	clock := domain.NewFakeClock(start.Add(time.Hour))
+	schedule := shiftTestSchedule(t, start)
+	south := domain.NewParkingLot(5, domain.WithID("SOUTH"), domain.WithClock(clock), domain.WithShiftSchedule(schedule))
+	john := domain.NewParkingAttendant("John Doe")
+
+	err := john.TryParkCar(south, domain.Car{Plate: "KA01AB0001"})
+	if !errors.Is(err, domain.ErrNotOnDuty) {
+		t.Errorf("Expected ErrNotOnDuty before John's shift at SOUTH, got %v", err)
+	}
+	if err := south.TryPark(domain.Car{Plate: "KA01AB0002"}); err != nil {
+		t.Errorf("Expected drivers to park themselves at any time, got %v", err)
+	}
+
+	clock.Advance(8 * time.Hour)
+	if err := john.TryParkCar(south, domain.Car{Plate: "KA01AB0001"}); err != nil {
+		t.Errorf("Expected John to park during his shift, got %v", err)
+	}
+}
+
+func TestShiftSchedule_Report_ShouldCountCarsPerAttendantPerShift(t *testing.T) {
+	start := time.Date(2024, 1, 1, 6, 0, 0, 0, time.UTC)
+	clock := domain.NewFakeClock(start.Add(time.Hour))
+	schedule := shiftTestSchedule(t, start)
+	history := domain.NewInMemoryHistoryStore()
+	north := domain.NewParkingLot(5, domain.WithID("NORTH"), domain.WithClock(clock), domain.WithShiftSchedule(schedule), domain.WithHistoryStore(history))
+	south := domain.NewParkingLot(5, domain.WithID("SOUTH"), domain.WithClock(clock), domain.WithShiftSchedule(schedule), domain.WithHistoryStore(history))
+	john := domain.NewParkingAttendant("John Doe")
+	jane := domain.NewParkingAttendant("Jane Roe")
+
+	john.ParkCar(north, domain.Car{Plate: "KA01AB0001", Make: "Toyota", Color: "Blue"})
+	john.ParkCar(north, domain.Car{Plate: "KA01AB0002"})
+	jane.ParkCar(south, domain.Car{Plate: "KA01AB0003", Make: "Toyota", Color: "Blue"})
+	clock.Advance(2 * time.Hour)
+	jane.UnparkCar(north, domain.Car{Plate: "KA01AB0002"})
+	clock.Advance(6 * time.Hour)
+	john.ParkCar(south, domain.Car{Plate: "KA01AB0004"})
+
+	reports := schedule.Report(history, []*domain.ParkingLot{north, south}, start, start.Add(24*time.Hour))
+	counts := make(map[string][2]int)
+	for _, report := range reports {
+		counts[report.Shift.ID] = [2]int{report.CarsParked, report.CarsReturned}
+	}
+	expected := map[string][2]int{"AM-JOHN": {2, 0}, "AM-JANE": {1, 1}, "PM-JOHN": {1, 0}}
+	for id, want := range expected {
+		if counts[id] != want {
+			t.Errorf("Shift %s: expected parked/returned %v, got %v", id, want, counts[id])
+		}
+	}
+
+	visits := history.Query(domain.VisitFilter{Plate: "KA01AB0002"})
+	if len(visits) != 1 || visits[0].AttendantName != "John Doe" || visits[0].ExitAttendantName != "Jane Roe" {
+		t.Errorf("Expected the visit to record who parked and returned the car, got %+v", visits)
+	}
+
+	// The robbery report names whoever parked each car, not the attendant passed in
+	police := domain.NewPoliceDepartment("City Police")
+	for _, found := range police.InvestigateBlueToyotas([]*domain.ParkingLot{north, south}, john) {
+		if want := map[string]string{"KA01AB0001": "John Doe", "KA01AB0003": "Jane Roe"}[found.Car.Plate]; found.AttendantName != want {
+			t.Errorf("Expected %s to be reported as parked by %s, got %s", found.Car.Plate, want, found.AttendantName)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package unit
+
+import (
+	"errors"
+	"parking-lot-system/internal/domain"
+	"path/filepath"
+	"testing"
+)
+
+func TestSlotSize_Fits_ShouldFollowCompatibilityRules(t *testing.T) {
+	tests := []struct {
+		slot domain.SlotSize
+		car  domain.CarSize
+		fits bool
+	}{
+		{domain.SmallSlot, domain.Small, true},
+		{domain.SmallSlot, domain.Medium, false},
+		{domain.MediumSlot, domain.Small, true},
+		{domain.MediumSlot, domain.Large, false},
+		{domain.LargeSlot, domain.Large, true},
+		{domain.AnySize, domain.Large, true},
+	}
+
+	for _, test := range tests {
+		if got := test.slot.Fits(test.car); got != test.fits {
+			t.Errorf("%s slot fits %s car: expected %v, got %v", test.slot, test.car, test.fits, got)
+		}
+	}
+}
+
+func TestParkingLot_Park_ShouldPutCarsInTheTightestSlot(t *testing.T) {
+	lot := domain.NewParkingLot(3, domain.WithSlotSizes(
+		domain.SlotBlock{Size: domain.LargeSlot, Slots: 1},
+		domain.SlotBlock{Size: domain.MediumSlot, Slots: 1},
+		domain.SlotBlock{Size: domain.SmallSlot, Slots: 1},
+	))
+
+	lot.Park(domain.Car{Plate: "S1", Size: domain.Small})
+	lot.Park(domain.Car{Plate: "S2", Size: domain.Small})
+	if lot.FindCar("S1") != 2 || lot.FindCar("S2") != 1 {
+		t.Errorf("Expected small cars in the small then the medium slot, got %d and %d", lot.FindCar("S1"), lot.FindCar("S2"))
+	}
+
+	err := lot.TryPark(domain.Car{Plate: "M1", Size: domain.Medium})
+	if lot.FindCar("M1") != 0 || err != nil {
+		t.Errorf("Expected the medium car in the large slot, got slot %d, %v", lot.FindCar("M1"), err)
+	}
+}
+
+func TestParkingLot_Park_ShouldRejectCarsThatFitNoFreeSlot(t *testing.T) {
+	lot := domain.NewParkingLot(2, domain.WithSlotSizes(domain.SlotBlock{Size: domain.MediumSlot, Slots: 2}))
+
+	err := lot.TryPark(domain.Car{Plate: "L1", Size: domain.Large})
+	if !errors.Is(err, domain.ErrLotFull) {
+		t.Errorf("Expected ErrLotFull for a large car, got %v", err)
+	}
+	if lot.CanFit(domain.Large) || !lot.CanFit(domain.Medium) {
+		t.Errorf("Expected room for medium cars only")
+	}
+}
+
+func TestParkingLot_Park_ShouldParkLargeCarAcrossTwoSmallSlots(t *testing.T) {
+	lot := domain.NewParkingLot(5,
+		domain.WithRows(domain.RowSpec{Name: "A", Slots: 3}, domain.RowSpec{Name: "B", Slots: 2}),
+		domain.WithSlotSizes(domain.SlotBlock{Size: domain.SmallSlot, Slots: 5}),
+	)
+	lot.Park(domain.Car{Plate: "S1", Size: domain.Small})
+	lot.Park(domain.Car{Plate: "S2", Size: domain.Small})
+
+	// Slot 2 ends row A, so the pair must be the two slots of row B
+	if err := lot.TryPark(domain.Car{Plate: "L1", Size: domain.Large}); err != nil {
+		t.Fatalf("Expected the large car to park across two small slots, got %v", err)
+	}
+	if lot.FindCar("L1") != 3 {
+		t.Errorf("Expected the large car from slot 3, got %d", lot.FindCar("L1"))
+	}
+	if lot.GetParkedCarsCount() != 3 || lot.GetAvailableSpaces() != 1 {
+		t.Errorf("Expected 3 cars and 1 free slot, got %d and %d", lot.GetParkedCarsCount(), lot.GetAvailableSpaces())
+	}
+	if cars := lot.GetAllParkedCars(); len(cars) != 3 {
+		t.Errorf("Expected the large car to be listed once, got %v", cars)
+	}
+
+	lot.Unpark(domain.Car{Plate: "L1"})
+	if lot.GetAvailableSpaces() != 3 || lot.GetAvailableSpacesFor(domain.Large) != 1 {
+		t.Errorf("Expected both slots back, got %d free and room for %d large cars",
+			lot.GetAvailableSpaces(), lot.GetAvailableSpacesFor(domain.Large))
+	}
+}
+
+func TestParkingLot_ShouldAccountCapacityPerSize(t *testing.T) {
+	lot := domain.NewParkingLot(6, domain.WithSlotSizes(
+		domain.SlotBlock{Size: domain.SmallSlot, Slots: 3},
+		domain.SlotBlock{Size: domain.LargeSlot, Slots: 2},
+	))
+	lot.Park(domain.Car{Plate: "L1", Size: domain.Large})
+
+	capacity := lot.GetCapacityBySize()
+	if capacity[domain.SmallSlot] != 3 || capacity[domain.LargeSlot] != 2 || capacity[domain.AnySize] != 1 {
+		t.Errorf("Unexpected capacity: %v", capacity)
+	}
+	// The unmarked slot is used before large slots, which are kept for cars that need them
+	free := lot.GetAvailableSpacesBySize()
+	if free[domain.AnySize] != 0 || free[domain.LargeSlot] != 2 || free[domain.SmallSlot] != 3 {
+		t.Errorf("Unexpected free slots: %v", free)
+	}
+	// Two large slots and one pair of small slots
+	if lot.GetAvailableSpacesFor(domain.Large) != 3 {
+		t.Errorf("Expected room for 3 large cars, got %d", lot.GetAvailableSpacesFor(domain.Large))
+	}
+}
+
+func TestParkingAttendant_ParkLargeCar_ShouldPickLotWithRoomForItsSize(t *testing.T) {
+	compact := domain.NewParkingLot(10, domain.WithSlotSizes(domain.SlotBlock{Size: domain.MediumSlot, Slots: 10}))
+	roomy := domain.NewParkingLot(2, domain.WithSlotSizes(domain.SlotBlock{Size: domain.LargeSlot, Slots: 2}))
+	lots := []*domain.ParkingLot{compact, roomy}
+	attendant := domain.NewParkingAttendant("John Doe")
+
+	if !attendant.ParkLargeCar(lots, domain.Car{Plate: "L1", Size: domain.Large}) || roomy.FindCar("L1") == -1 {
+		t.Errorf("Expected the large car in the lot with large slots")
+	}
+	if !attendant.ParkCarEvenly(lots, domain.Car{Plate: "L2", Size: domain.Large}) || roomy.FindCar("L2") == -1 {
+		t.Errorf("Expected even parking to skip lots the car does not fit")
+	}
+	err := attendant.TryParkHandicapCar(lots, domain.Car{Plate: "L3", Size: domain.Large})
+	if !errors.Is(err, domain.ErrLotFull) {
+		t.Errorf("Expected ErrLotFull once no large slot is free, got %v", err)
+	}
+}
+
+func TestParkingLot_Snapshot_ShouldRestoreLargeCarAcrossTwoSlots(t *testing.T) {
+	sizes := domain.WithSlotSizes(domain.SlotBlock{Size: domain.SmallSlot, Slots: 4})
+	lot := domain.NewParkingLot(4, sizes)
+	lot.Park(domain.Car{Plate: "L1", Size: domain.Large})
+
+	restored, err := domain.RestoreSnapshot(lot.Snapshot(), sizes)
+	if err != nil {
+		t.Fatalf("Expected restore to succeed, got %v", err)
+	}
+	if restored.GetAvailableSpaces() != 2 || restored.GetParkedCarsCount() != 1 {
+		t.Errorf("Expected the large car to hold two slots, got %d free", restored.GetAvailableSpaces())
+	}
+	if err := restored.TryPark(domain.Car{Plate: "L2", Size: domain.Large}); err != nil || restored.FindCar("L2") != 2 {
+		t.Errorf("Expected a second large car in slots 2-3, got %d, %v", restored.FindCar("L2"), err)
+	}
+}
+
+func TestRestore_ShouldRejectCarsTheirSlotNoLongerAccepts(t *testing.T) {
+	lot := domain.NewParkingLot(2, domain.WithSlotSizes(domain.SlotBlock{Size: domain.LargeSlot, Slots: 2}))
+	lot.Park(domain.Car{Plate: "L1", Size: domain.Large})
+	smallSlots := domain.WithSlotSizes(domain.SlotBlock{Size: domain.SmallSlot, Slots: 2})
+
+	if _, err := domain.RestoreSnapshot(lot.Snapshot(), smallSlots); !errors.Is(err, domain.ErrCorruptSnapshot) {
+		t.Errorf("Expected ErrCorruptSnapshot for a large car in a small slot, got %v", err)
+	}
+	pair := lot.Snapshot()
+	pair.Cars[0].SpansTwoSlots = true
+	if _, err := domain.RestoreSnapshot(pair, domain.WithSlotSizes(domain.SlotBlock{Size: domain.MediumSlot, Slots: 2})); !errors.Is(err, domain.ErrCorruptSnapshot) {
+		t.Errorf("Expected ErrCorruptSnapshot for a large car across two medium slots, got %v", err)
+	}
+}
+
+func TestReplayJournal_ShouldRejectCarsTheirSlotNoLongerAccepts(t *testing.T) {
+	journal, journalPath := openTestJournal(t)
+	snapshotPath := filepath.Join(t.TempDir(), "lot.json")
+	smallSlots := domain.WithSlotSizes(domain.SlotBlock{Size: domain.SmallSlot, Slots: 2})
+	lot := domain.NewParkingLot(2, domain.WithSlotSizes(domain.SlotBlock{Size: domain.LargeSlot, Slots: 2}))
+	lot.AttachJournal(journal)
+	lot.SaveSnapshotFile(snapshotPath)
+	lot.Park(domain.Car{Plate: "L1", Size: domain.Large})
+
+	if _, err := domain.RecoverLot(snapshotPath, journalPath, smallSlots); !errors.Is(err, domain.ErrJournalCorrupt) {
+		t.Errorf("Expected ErrJournalCorrupt for a large car in a small slot, got %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package unit
+
+import (
+	"parking-lot-system/internal/domain"
+	"testing"
+)
+
+// Slot IDs must not shift when an earlier car leaves the lot
+func TestParkingLot_FindCar_ShouldKeepSlotID_WhenEarlierCarUnparks(t *testing.T) {
+	lot := domain.NewParkingLot(3)
+	car1 := domain.Car{Plate: "MH12AB1234", Make: "Toyota", Color: "Blue"}
+	car2 := domain.Car{Plate: "MH12AB5678", Make: "Honda", Color: "White"}
+
+	lot.Park(car1)
+	lot.Park(car2)
+	lot.Unpark(car1)
+
+	if slotID := lot.FindCar(car2.Plate); slotID != 1 {
+		t.Errorf("Expected car to stay in slot 1, got %d", slotID)
+	}
+}
+
+// A freed slot is reused by the next car that parks
+func TestParkingLot_Park_ShouldReuseLowestFreeSlot(t *testing.T) {
+	lot := domain.NewParkingLot(3)
+	car1 := domain.Car{Plate: "MH12AB1234", Make: "Toyota", Color: "Blue"}
+	car2 := domain.Car{Plate: "MH12AB5678", Make: "Honda", Color: "White"}
+	car3 := domain.Car{Plate: "MH12AB9999", Make: "BMW", Color: "Black"}
+
+	lot.Park(car1)
+	lot.Park(car2)
+	lot.Unpark(car1)
+	lot.Park(car3)
+
+	if slotID := lot.FindCar(car3.Plate); slotID != 0 {
+		t.Errorf("Expected car to take freed slot 0, got %d", slotID)
+	}
+	slot, ok := lot.GetSlot(0)
+	if !ok || !slot.IsOccupied() {
+		t.Fatalf("Expected slot 0 to be occupied")
+	}
+	if car, _ := slot.GetCar(); car.Plate != car3.Plate {
+		t.Errorf("Expected slot 0 to hold %s, got %s", car3.Plate, car.Plate)
+	}
+}
+
+func TestParkingLot_ParkInRow_ShouldReportStableSlotID(t *testing.T) {
+	lot := domain.NewParkingLot(3)
+	car1 := domain.Car{Plate: "MH12AB1234", Make: "Toyota", Color: "Blue", Size: domain.Small}
+	car2 := domain.Car{Plate: "MH12AB5678", Make: "Honda", Color: "White", Size: domain.Small}
+
+	lot.ParkInRow(car1, "A", false)
+	lot.ParkInRow(car2, "B", true)
+	lot.Unpark(car1)
+
+	matches := lot.FindSmallHandicapCarsInRows([]string{"B"})
+	if len(matches) != 1 {
+		t.Fatalf("Expected 1 match, got %d", len(matches))
+	}
+	if matches[0].SlotID != 1 {
+		t.Errorf("Expected slot 1, got %d", matches[0].SlotID)
+	}
+}
+
+func TestParkingLot_Park_ShouldRejectPlateAlreadyParked(t *testing.T) {
+	lot := domain.NewParkingLot(3)
+	car := domain.Car{Plate: "MH12AB1234", Make: "Toyota", Color: "Blue"}
+
+	lot.Park(car)
+	if lot.Park(car) {
+		t.Errorf("Expected second park of same plate to fail")
+	}
+	if lot.GetParkedCarsCount() != 1 {
+		t.Errorf("Expected 1 parked car, got %d", lot.GetParkedCarsCount())
+	}
+}
+
+func TestPoliceDepartment_InvestigateWhiteCars_ShouldReportStableSlotIDs(t *testing.T) {
+	lot := domain.NewParkingLot(3)
+	police := domain.NewPoliceDepartment("City Police")
+	blueCar := domain.Car{Plate: "MH12AB1234", Make: "Toyota", Color: "Blue"}
+	whiteCar := domain.Car{Plate: "MH12AB5678", Make: "Honda", Color: "White"}
+
+	lot.Park(blueCar)
+	lot.Park(whiteCar)
+	lot.Unpark(blueCar)
+
+	locations := police.InvestigateWhiteCars([]*domain.ParkingLot{lot})
+	if len(locations) != 1 || locations[0].SlotID != 1 {
+		t.Errorf("Expected white car in slot 1, got %+v", locations)
+	}
+}
@@ -0,0 +1,112 @@
+package unit
+
+import (
+	"bytes"
+	"errors"
+	"parking-lot-system/internal/domain"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParkingLot_SnapshotFile_ShouldRestoreIdenticalState(t *testing.T) {
+	clock := domain.NewFakeClock(time.Date(2025, 7, 9, 10, 0, 0, 0, time.UTC))
+	lot := domain.NewParkingLot(5, domain.WithClock(clock))
+	attendant := domain.NewParkingAttendant("John Doe")
+
+	car1 := domain.Car{Plate: "MH12AB1234", Make: "Toyota", Color: "Blue", Size: domain.Small}
+	car2 := domain.Car{Plate: "MH12AB5678", Make: "Honda", Color: "White", Size: domain.Medium}
+	car3 := domain.Car{Plate: "MH12AB9999", Make: "BMW", Color: "Black", Size: domain.Large}
+
+	lot.Park(car1)
+	clock.Advance(10 * time.Minute)
+	lot.ParkInRow(car2, "B", true)
+	ticket, _ := attendant.ParkCarWithTicket(lot, car3)
+	lot.Unpark(car1)
+
+	path := filepath.Join(t.TempDir(), "lot.json")
+	if err := lot.SaveSnapshotFile(path); err != nil {
+		t.Fatalf("Expected snapshot to be saved, got %v", err)
+	}
+
+	owner := &MockOwner{}
+	restored, err := domain.RestoreFile(path, domain.WithClock(clock), domain.WithOwnerObserver(owner))
+	if err != nil {
+		t.Fatalf("Expected restore to succeed, got %v", err)
+	}
+
+	if restored.GetID() != lot.GetID() {
+		t.Errorf("Expected lot ID %s, got %s", lot.GetID(), restored.GetID())
+	}
+	if restored.GetParkedCarsCount() != 2 || restored.GetAvailableSpaces() != 3 {
+		t.Errorf("Expected 2 cars and 3 free spaces, got %d and %d", restored.GetParkedCarsCount(), restored.GetAvailableSpaces())
+	}
+	if restored.FindCar(car2.Plate) != 1 || restored.FindCar(car3.Plate) != 2 {
+		t.Errorf("Expected slots 1 and 2, got %d and %d", restored.FindCar(car2.Plate), restored.FindCar(car3.Plate))
+	}
+	if !restored.GetParkingTime(car2.Plate).Equal(lot.GetParkingTime(car2.Plate)) {
+		t.Errorf("Expected parking time to survive restore")
+	}
+	if len(restored.FindSmallHandicapCarsInRows([]string{"B"})) != 0 {
+		t.Errorf("Medium car should not match small handicap search")
+	}
+	if matches := restored.FindCarsByColor("White"); len(matches) != 1 || matches[0].Size != domain.Medium {
+		t.Errorf("Expected restored white medium car, got %+v", matches)
+	}
+
+	// Tickets issued before the restart are still honoured
+	if err := restored.UnparkByTicket(ticket); err != nil {
+		t.Errorf("Expected ticket to be accepted after restore, got %v", err)
+	}
+}
+
+func TestRestore_ShouldKeepRowAndHandicapFlags(t *testing.T) {
+	lot := domain.NewParkingLot(3)
+	lot.ParkInRow(domain.Car{Plate: "MH12AB1234", Size: domain.Small}, "D", true)
+
+	var buf bytes.Buffer
+	if err := lot.SaveSnapshot(&buf); err != nil {
+		t.Fatalf("Expected snapshot to be written, got %v", err)
+	}
+	restored, err := domain.Restore(&buf)
+	if err != nil {
+		t.Fatalf("Expected restore to succeed, got %v", err)
+	}
+
+	matches := restored.FindSmallHandicapCarsInRows([]string{"D"})
+	if len(matches) != 1 || matches[0].Row != "D" || !matches[0].IsHandicap {
+		t.Errorf("Expected handicap car in row D, got %+v", matches)
+	}
+}
+
+func TestRestore_ShouldReregisterWithPlateRegistry(t *testing.T) {
+	lot := domain.NewParkingLot(3)
+	lot.Park(domain.Car{Plate: "MH12AB1234"})
+	snapshot := lot.Snapshot()
+
+	other := domain.NewParkingLot(3)
+	registry := domain.NewPlateRegistry(other)
+	if _, err := domain.RestoreSnapshot(snapshot, domain.WithPlateRegistry(registry)); err != nil {
+		t.Fatalf("Expected restore to succeed, got %v", err)
+	}
+
+	if other.Park(domain.Car{Plate: "MH12AB1234"}) {
+		t.Errorf("Expected restored plate to block a duplicate in another lot")
+	}
+}
+
+func TestRestore_ShouldRejectUnknownVersionAndCorruptData(t *testing.T) {
+	if _, err := domain.Restore(strings.NewReader(`{"version": 99, "capacity": 1}`)); !errors.Is(err, domain.ErrUnsupportedSnapshotVersion) {
+		t.Errorf("Expected ErrUnsupportedSnapshotVersion, got %v", err)
+	}
+	if _, err := domain.Restore(strings.NewReader(`{"version": 1`)); !errors.Is(err, domain.ErrCorruptSnapshot) {
+		t.Errorf("Expected ErrCorruptSnapshot for truncated file, got %v", err)
+	}
+	twice := `{"version": 1, "capacity": 2, "cars": [
+		{"car": {"Plate": "A1"}, "slot_id": 0},
+		{"car": {"Plate": "B2"}, "slot_id": 0}]}`
+	if _, err := domain.Restore(strings.NewReader(twice)); !errors.Is(err, domain.ErrCorruptSnapshot) {
+		t.Errorf("Expected ErrCorruptSnapshot for shared slot, got %v", err)
+	}
+}
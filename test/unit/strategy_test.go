@@ -0,0 +1,84 @@
+package unit
+
+import (
+	"parking-lot-system/internal/domain"
+	"testing"
+)
+
+func strategyTestLots() (near, middle, far *domain.ParkingLot) {
+	near = domain.NewParkingLot(4, domain.WithID("NEAR"), domain.WithSlotSizes(domain.SlotBlock{Size: domain.SmallSlot, Slots: 4}))
+	middle = domain.NewParkingLot(3, domain.WithID("MIDDLE"), domain.WithSlotSizes(domain.SlotBlock{Size: domain.MediumSlot, Slots: 3}))
+	far = domain.NewParkingLot(6, domain.WithID("FAR"), domain.WithSlotSizes(domain.SlotBlock{Size: domain.LargeSlot, Slots: 6}))
+	near.Park(domain.Car{Plate: "N1", Size: domain.Small})
+	near.Park(domain.Car{Plate: "N2", Size: domain.Small})
+	return near, middle, far
+}
+
+func TestParkingStrategies_ShouldChooseLotsByTheirPolicy(t *testing.T) {
+	near, middle, far := strategyTestLots()
+	lots := []*domain.ParkingLot{near, middle, far}
+	small := domain.ParkingRequest{Vehicle: domain.Car{Plate: "S1", Size: domain.Small}}
+	medium := domain.ParkingRequest{Vehicle: domain.Car{Plate: "M1", Size: domain.Medium}}
+
+	tests := []struct {
+		name     string
+		strategy domain.ParkingStrategy
+		req      domain.ParkingRequest
+		expected *domain.ParkingLot
+	}{
+		{"nearest first", domain.NearestFirstStrategy{}, small, near},
+		{"even", domain.EvenStrategy{}, small, middle},
+		{"most space", domain.MostSpaceStrategy{}, small, far},
+		{"fill first", domain.FillFirstStrategy{}, small, near},
+		{"best fit", domain.BestFitStrategy{}, medium, middle},
+		{"best fit for a small car", domain.BestFitStrategy{}, small, near},
+	}
+
+	for _, test := range tests {
+		if got := test.strategy.ChooseLot(lots, test.req); got != test.expected {
+			t.Errorf("%s: expected %s, got %v", test.name, test.expected.GetID(), got)
+		}
+	}
+}
+
+func TestDispatchStrategy_ShouldPickStrategyByVehicle(t *testing.T) {
+	near, middle, far := strategyTestLots()
+	lots := []*domain.ParkingLot{near, middle, far}
+	dispatcher := domain.NewDispatchStrategy(domain.FillFirstStrategy{},
+		domain.StrategyRule{Matches: domain.IsVehicleType(domain.VehicleMotorcycle), Strategy: domain.EvenStrategy{}},
+		domain.StrategyRule{Matches: domain.IsLarge, Strategy: domain.MostSpaceStrategy{}},
+	)
+
+	if got := dispatcher.ChooseLot(lots, domain.ParkingRequest{Vehicle: domain.Motorcycle{Plate: "MC1"}}); got != middle {
+		t.Errorf("Expected motorcycles spread evenly, got %v", got)
+	}
+	if got := dispatcher.ChooseLot(lots, domain.ParkingRequest{Vehicle: domain.Van{Plate: "V1"}}); got != far {
+		t.Errorf("Expected vans in the lot with the most room, got %v", got)
+	}
+	if got := dispatcher.ChooseLot(lots, domain.ParkingRequest{Vehicle: domain.Car{Plate: "C1"}}); got != near {
+		t.Errorf("Expected other cars to fill the first lot, got %v", got)
+	}
+}
+
+func TestParkingAttendant_Park_ShouldUseItsStrategy(t *testing.T) {
+	near, middle, far := strategyTestLots()
+	lots := []*domain.ParkingLot{near, middle, far}
+	custom := domain.StrategyFunc(func(lots []*domain.ParkingLot, req domain.ParkingRequest) *domain.ParkingLot {
+		return lots[len(lots)-1]
+	})
+	attendant := domain.NewParkingAttendant("John Doe", domain.WithStrategy(custom))
+
+	ticket, err := attendant.Park(lots, domain.ParkingRequest{Vehicle: domain.Car{Plate: "C1", Size: domain.Small}})
+	if err != nil || ticket.LotID != "FAR" || ticket.AttendantName != "John Doe" {
+		t.Errorf("Expected the custom strategy to pick the far lot, got %+v, %v", ticket, err)
+	}
+
+	// The default strategy sends large vehicles to the lot with the most room
+	attendant = domain.NewParkingAttendant("Jane Doe")
+	if ticket, err := attendant.Park(lots, domain.ParkingRequest{Vehicle: domain.Van{Plate: "V1"}}); err != nil || ticket.LotID != "FAR" {
+		t.Errorf("Expected the van in the far lot, got %+v, %v", ticket, err)
+	}
+	if ticket, err := attendant.Park(lots, domain.ParkingRequest{Vehicle: domain.Car{Plate: "C2", Size: domain.Small}}); err != nil || ticket.LotID != "NEAR" {
+		t.Errorf("Expected a small car in the nearest lot, got %+v, %v", ticket, err)
+	}
+}
@@ -0,0 +1,110 @@
+package unit
+
+import (
+	"parking-lot-system/internal/domain"
+	"testing"
+	"time"
+)
+
+func standardTariff() domain.StandardTariff {
+	return domain.StandardTariff{
+		HourlyRates: map[domain.CarSize]domain.Money{
+			domain.Small:  3000,
+			domain.Medium: 4000,
+			domain.Large:  6000,
+		},
+		FreeMinutes: 15,
+		DailyCaps: map[domain.CarSize]domain.Money{
+			domain.Small:  20000,
+			domain.Medium: 25000,
+			domain.Large:  40000,
+		},
+		Overnight: &domain.OvernightRate{StartHour: 22, EndHour: 6, Flat: 10000},
+	}
+}
+
+func totalOf(items []domain.LineItem) domain.Money {
+	var total domain.Money
+	for _, item := range items {
+		total += item.Amount
+	}
+	return total
+}
+
+func TestStandardTariff_ShouldBeFree_WithinFreeMinutes(t *testing.T) {
+	entry := time.Date(2025, 7, 9, 10, 0, 0, 0, time.UTC)
+	stay := domain.Stay{Size: domain.Medium, EntryTime: entry, ExitTime: entry.Add(10 * time.Minute)}
+
+	if total := totalOf(standardTariff().Charge(stay)); total != 0 {
+		t.Errorf("Expected free stay, got %s", total)
+	}
+}
+
+func TestStandardTariff_ShouldChargePerStartedHour_BySize(t *testing.T) {
+	entry := time.Date(2025, 7, 9, 10, 0, 0, 0, time.UTC)
+	exit := entry.Add(15*time.Minute + 2*time.Hour + 1*time.Minute)
+
+	small := domain.Stay{Size: domain.Small, EntryTime: entry, ExitTime: exit}
+	large := domain.Stay{Size: domain.Large, EntryTime: entry, ExitTime: exit}
+
+	if total := totalOf(standardTariff().Charge(small)); total != 9000 {
+		t.Errorf("Expected 3h at 30.00 = 90.00, got %s", total)
+	}
+	if total := totalOf(standardTariff().Charge(large)); total != 18000 {
+		t.Errorf("Expected 3h at 60.00 = 180.00, got %s", total)
+	}
+}
+
+func TestStandardTariff_ShouldApplyDailyCap(t *testing.T) {
+	entry := time.Date(2025, 7, 9, 7, 0, 0, 0, time.UTC)
+	stay := domain.Stay{Size: domain.Medium, EntryTime: entry, ExitTime: entry.Add(14 * time.Hour)}
+
+	if total := totalOf(standardTariff().Charge(stay)); total != 25000 {
+		t.Errorf("Expected capped 250.00, got %s", total)
+	}
+}
+
+func TestStandardTariff_ShouldChargeOvernightFlatRate(t *testing.T) {
+	entry := time.Date(2025, 7, 9, 20, 45, 0, 0, time.UTC)
+	exit := time.Date(2025, 7, 10, 7, 30, 0, 0, time.UTC)
+	stay := domain.Stay{Size: domain.Small, EntryTime: entry, ExitTime: exit}
+
+	// 21:00-22:00 and 06:00-07:30 hourly (1h + 2h), 22:00-06:00 flat
+	want := domain.Money(10000 + 3*3000)
+	if total := totalOf(standardTariff().Charge(stay)); total != want {
+		t.Errorf("Expected %s, got %s", want, total)
+	}
+}
+
+func TestParkingLot_CheckOut_ShouldReturnItemisedReceipt(t *testing.T) {
+	clock := domain.NewFakeClock(time.Date(2025, 7, 9, 10, 0, 0, 0, time.UTC))
+	lot := domain.NewParkingLot(10, domain.WithClock(clock))
+	lot.SetTariff(domain.StandardTariff{
+		HourlyRates: map[domain.CarSize]domain.Money{domain.Medium: 4000},
+	})
+	car := domain.Car{Plate: "MH12AB1234", Make: "Toyota", Color: "Blue", Size: domain.Medium}
+	ticket, _ := lot.ParkWithTicket(car)
+	clock.Advance(90 * time.Minute)
+
+	receipt, err := lot.CheckOut(ticket)
+	if err != nil {
+		t.Fatalf("Expected checkout to succeed, got %v", err)
+	}
+	if receipt.TicketID != ticket.ID || receipt.Plate != car.Plate || receipt.LotID != lot.GetID() {
+		t.Errorf("Unexpected receipt details: %+v", receipt)
+	}
+	if receipt.Total != 8000 || len(receipt.Items) != 1 {
+		t.Errorf("Expected one item totalling 80.00, got %s in %d items", receipt.Total, len(receipt.Items))
+	}
+}
+
+func TestParkingLot_UnparkWithReceipt_ShouldBeFree_WithoutTariff(t *testing.T) {
+	lot := domain.NewParkingLot(10)
+	car := domain.Car{Plate: "MH12AB1234"}
+	lot.Park(car)
+
+	receipt, err := lot.UnparkWithReceipt(car)
+	if err != nil || receipt.Total != 0 {
+		t.Errorf("Expected free receipt, got %s (%v)", receipt.Total, err)
+	}
+}
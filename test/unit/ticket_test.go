@@ -0,0 +1,123 @@
+package unit
+
+import (
+	"errors"
+	"parking-lot-system/internal/domain"
+	"testing"
+)
+
+func TestParkingAttendant_ParkCarWithTicket_ShouldIssueCompleteTicket(t *testing.T) {
+	lot := domain.NewParkingLot(10)
+	attendant := domain.NewParkingAttendant("John Doe")
+	car := domain.Car{Plate: "MH12AB1234", Make: "Toyota", Color: "Blue"}
+
+	lot.Park(domain.Car{Plate: "MH12AB0001", Make: "BMW", Color: "Black"})
+	ticket, err := attendant.ParkCarWithTicket(lot, car)
+	if err != nil {
+		t.Fatalf("Expected car to be parked, got %v", err)
+	}
+
+	if ticket.ID == "" {
+		t.Errorf("Expected ticket ID to be set")
+	}
+	if ticket.LotID != lot.GetID() || ticket.SlotID != 1 || ticket.Plate != car.Plate {
+		t.Errorf("Unexpected ticket details: %+v", ticket)
+	}
+	if ticket.AttendantName != "John Doe" {
+		t.Errorf("Expected attendant John Doe, got %q", ticket.AttendantName)
+	}
+	if !ticket.EntryTime.Equal(lot.GetParkingTime(car.Plate)) {
+		t.Errorf("Expected entry time to match parking time")
+	}
+}
+
+func TestParkingLot_ParkWithTicket_ShouldIssueUniqueTicketIDs(t *testing.T) {
+	lot := domain.NewParkingLot(10)
+	ticket1, _ := lot.ParkWithTicket(domain.Car{Plate: "MH12AB1234"})
+	ticket2, _ := lot.ParkWithTicket(domain.Car{Plate: "MH12AB5678"})
+
+	if ticket1.ID == ticket2.ID {
+		t.Errorf("Expected unique ticket IDs")
+	}
+}
+
+func TestParkingLot_UnparkByTicket_ShouldReleaseCar(t *testing.T) {
+	lot := domain.NewParkingLot(10)
+	ticket, _ := lot.ParkWithTicket(domain.Car{Plate: "MH12AB1234", Make: "Toyota", Color: "Blue"})
+
+	if err := lot.UnparkByTicket(ticket); err != nil {
+		t.Fatalf("Expected unpark to succeed, got %v", err)
+	}
+	if lot.GetParkedCarsCount() != 0 {
+		t.Errorf("Expected lot to be empty")
+	}
+}
+
+func TestParkingLot_UnparkByTicket_ShouldRejectReplayedTicket(t *testing.T) {
+	lot := domain.NewParkingLot(10)
+	car := domain.Car{Plate: "MH12AB1234", Make: "Toyota", Color: "Blue"}
+	ticket, _ := lot.ParkWithTicket(car)
+	lot.UnparkByTicket(ticket)
+
+	// Same plate parks again; the old ticket must not release it
+	lot.Park(car)
+	if err := lot.UnparkByTicket(ticket); !errors.Is(err, domain.ErrTicketAlreadyUsed) {
+		t.Errorf("Expected ErrTicketAlreadyUsed, got %v", err)
+	}
+	if lot.FindCar(car.Plate) == -1 {
+		t.Errorf("Expected car to remain parked")
+	}
+}
+
+func TestParkingLot_UnparkByTicket_ShouldRejectForgedTicket(t *testing.T) {
+	lot := domain.NewParkingLot(10)
+	other := domain.NewParkingLot(10)
+	ticket, _ := lot.ParkWithTicket(domain.Car{Plate: "MH12AB1234"})
+	lot.Park(domain.Car{Plate: "MH12AB5678"})
+
+	forged := ticket
+	forged.Plate = "MH12AB5678"
+	if err := lot.UnparkByTicket(forged); !errors.Is(err, domain.ErrInvalidTicket) {
+		t.Errorf("Expected ErrInvalidTicket for altered plate, got %v", err)
+	}
+	if err := lot.UnparkByTicket(domain.Ticket{ID: "made-up", Plate: "MH12AB1234"}); !errors.Is(err, domain.ErrInvalidTicket) {
+		t.Errorf("Expected ErrInvalidTicket for unknown ticket, got %v", err)
+	}
+	if err := other.UnparkByTicket(ticket); !errors.Is(err, domain.ErrInvalidTicket) {
+		t.Errorf("Expected ErrInvalidTicket from another lot, got %v", err)
+	}
+	if lot.GetParkedCarsCount() != 2 {
+		t.Errorf("Expected both cars to remain parked")
+	}
+}
+
+func TestParkingLot_Unpark_ShouldVoidTicket(t *testing.T) {
+	lot := domain.NewParkingLot(10)
+	car := domain.Car{Plate: "MH12AB1234"}
+	ticket, _ := lot.ParkWithTicket(car)
+
+	lot.Unpark(car)
+	if err := lot.UnparkByTicket(ticket); !errors.Is(err, domain.ErrTicketAlreadyUsed) {
+		t.Errorf("Expected ErrTicketAlreadyUsed, got %v", err)
+	}
+}
+
+func TestParkingLot_WithTicketRequired_ShouldOnlyReleaseAgainstTicket(t *testing.T) {
+	lot := domain.NewParkingLot(10, domain.WithTicketRequired())
+	attendant := domain.NewParkingAttendant("John Doe")
+	car := domain.Car{Plate: "MH12AB1234"}
+	ticket, _ := lot.ParkWithTicket(car)
+
+	if err := lot.TryUnpark(car); !errors.Is(err, domain.ErrTicketRequired) {
+		t.Errorf("Expected ErrTicketRequired for a driver without a ticket, got %v", err)
+	}
+	if err := attendant.TryUnparkCar(lot, car); !errors.Is(err, domain.ErrTicketRequired) {
+		t.Errorf("Expected ErrTicketRequired for an attendant without a ticket, got %v", err)
+	}
+	if lot.GetParkedCarsCount() != 1 {
+		t.Fatalf("Expected the car to stay parked")
+	}
+	if err := lot.UnparkByTicket(ticket); err != nil {
+		t.Errorf("Expected the ticket to release the car, got %v", err)
+	}
+}
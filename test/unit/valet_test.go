@@ -0,0 +1,129 @@
+package unit
+
+import (
+	"errors"
+	"parking-lot-system/internal/domain"
+	"testing"
+	"time"
+)
+
+func valetTestService(clock domain.Clock, hooks int) (*domain.ValetService, *domain.ParkingLot, []*domain.ParkingAttendant) {
+	lot := domain.NewParkingLot(5, domain.WithID("PREMIUM"), domain.WithClock(clock), domain.WithValetOnly(), domain.WithTicketRequired())
+	attendants := []*domain.ParkingAttendant{domain.NewParkingAttendant("John Doe"), domain.NewParkingAttendant("Jane Roe")}
+	service := domain.NewValetService([]*domain.ParkingLot{lot}, attendants,
+		domain.WithValetClock(clock),
+		domain.WithKeyCabinet(domain.NewKeyCabinet(hooks)),
+		domain.WithRetrievalTime(4*time.Minute),
+	)
+	return service, lot, attendants
+}
+
+func TestParkingLot_WithValetOnly_ShouldTurnDriversAway(t *testing.T) {
+	clock := domain.NewFakeClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	_, lot, attendants := valetTestService(clock, 5)
+
+	if err := lot.TryPark(domain.Car{Plate: "KA01AB0001"}); !errors.Is(err, domain.ErrValetOnly) {
+		t.Errorf("Expected ErrValetOnly for a driver, got %v", err)
+	}
+	attendants[0].ParkCar(lot, domain.Car{Plate: "KA01AB0001"})
+	if err := lot.TryUnpark(domain.Car{Plate: "KA01AB0001"}); !errors.Is(err, domain.ErrValetOnly) {
+		t.Errorf("Expected drivers not to collect cars themselves, got %v", err)
+	}
+}
+
+func TestValetService_ShouldParkQueueAndReturnCars(t *testing.T) {
+	clock := domain.NewFakeClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	service, lot, attendants := valetTestService(clock, 5)
+
+	first, err := service.DropOff("A. Rao", domain.Car{Plate: "KA01AB0001"})
+	if err != nil {
+		t.Fatalf("Expected the drop-off to succeed, got %v", err)
+	}
+	second, _ := service.DropOff("B. Shah", domain.Car{Plate: "KA01AB0002"})
+	third, _ := service.DropOff("C. Iyer", domain.Car{Plate: "KA01AB0003"})
+	if first.ParkedBy != "John Doe" || second.ParkedBy != "Jane Roe" || third.ParkedBy != "John Doe" {
+		t.Errorf("Expected attendants to be dispatched in turn, got %s, %s, %s", first.ParkedBy, second.ParkedBy, third.ParkedBy)
+	}
+	if first.Hook != 1 || second.Hook != 2 || lot.FindCar("KA01AB0002") != second.SlotID {
+		t.Errorf("Expected keys on hooks 1 and 2, got %d and %d", first.Hook, second.Hook)
+	}
+
+	// Two attendants fetch two cars per round
+	service.RequestRetrieval(second.ID)
+	service.RequestRetrieval(first.ID)
+	request, err := service.RequestRetrieval(third.ID)
+	if err != nil || request.Position != 3 || request.EstimatedWait != 8*time.Minute {
+		t.Errorf("Expected third in line with an 8 minute wait, got %+v, %v", request, err)
+	}
+	if _, err := service.RequestRetrieval(third.ID); !errors.Is(err, domain.ErrRetrievalRequested) {
+		t.Errorf("Expected ErrRetrievalRequested when asking twice, got %v", err)
+	}
+
+	clock.Advance(time.Hour)
+	returned, _, err := service.RetrieveNext(attendants[0])
+	if err != nil || returned.ID != second.ID || returned.Status != domain.ValetReturned {
+		t.Errorf("Expected the first requested car back, got %+v, %v", returned, err)
+	}
+	if lot.FindCar("KA01AB0002") != -1 {
+		t.Errorf("Expected the car to have left the lot")
+	}
+	if queue := service.GetRetrievalQueue(); len(queue) != 2 || queue[0].TicketID != first.ID || queue[1].EstimatedWait != 4*time.Minute {
+		t.Errorf("Expected the queue to move up, got %+v", queue)
+	}
+
+	handoffs := service.GetKeyHandoffs(second.ID)
+	expected := []struct{ from, to string }{
+		{"B. Shah", "Jane Roe"},
+		{"Jane Roe", "Cabinet hook 2"},
+		{"Cabinet hook 2", "John Doe"},
+		{"John Doe", "B. Shah"},
+	}
+	if len(handoffs) != len(expected) {
+		t.Fatalf("Expected %d key handoffs, got %+v", len(expected), handoffs)
+	}
+	for i, want := range expected {
+		if handoffs[i].From != want.from || handoffs[i].To != want.to {
+			t.Errorf("Handoff %d: expected %s to %s, got %s to %s", i, want.from, want.to, handoffs[i].From, handoffs[i].To)
+		}
+	}
+}
+
+func TestValetService_DropOff_ShouldFreeTheHookWhenTheCarCannotPark(t *testing.T) {
+	clock := domain.NewFakeClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	lot := domain.NewParkingLot(1, domain.WithClock(clock), domain.WithValetOnly())
+	cabinet := domain.NewKeyCabinet(3)
+	service := domain.NewValetService([]*domain.ParkingLot{lot}, []*domain.ParkingAttendant{domain.NewParkingAttendant("John Doe")},
+		domain.WithValetClock(clock), domain.WithKeyCabinet(cabinet))
+
+	service.DropOff("A. Rao", domain.Car{Plate: "KA01AB0001"})
+	if _, err := service.DropOff("B. Shah", domain.Car{Plate: "KA01AB0002"}); !errors.Is(err, domain.ErrLotFull) {
+		t.Errorf("Expected ErrLotFull, got %v", err)
+	}
+	if _, hung := cabinet.HookOf("KA01AB0002"); hung || cabinet.GetFreeHooks() != 2 {
+		t.Errorf("Expected the hook to be freed, got %d free", cabinet.GetFreeHooks())
+	}
+}
+
+func TestValetService_DropOff_ShouldTurnCarAwayWhenCabinetIsFull(t *testing.T) {
+	clock := domain.NewFakeClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	owner := &MockOwner{}
+	history := domain.NewInMemoryHistoryStore()
+	lot := domain.NewParkingLot(1, domain.WithClock(clock), domain.WithValetOnly(),
+		domain.WithOwnerObserver(owner), domain.WithHistoryStore(history))
+	service := domain.NewValetService([]*domain.ParkingLot{lot}, []*domain.ParkingAttendant{domain.NewParkingAttendant("John Doe")},
+		domain.WithValetClock(clock), domain.WithKeyCabinet(domain.NewKeyCabinet(0)))
+
+	if _, err := service.DropOff("B. Shah", domain.Car{Plate: "KA01AB0002"}); !errors.Is(err, domain.ErrCabinetFull) {
+		t.Errorf("Expected ErrCabinetFull, got %v", err)
+	}
+	if lot.GetParkedCarsCount() != 0 || owner.WasNotified || owner.SpaceNotified || history.Len() != 0 {
+		t.Errorf("Expected the car never to be parked, got %d cars, owner %+v and %d visits",
+			lot.GetParkedCarsCount(), owner, history.Len())
+	}
+	if _, err := service.DropOff("B. Shah", nil); !errors.Is(err, domain.ErrInvalidCar) {
+		t.Errorf("Expected ErrInvalidCar for a nil vehicle, got %v", err)
+	}
+	if _, err := service.RequestRetrieval("forged"); !errors.Is(err, domain.ErrUnknownValetTicket) {
+		t.Errorf("Expected ErrUnknownValetTicket, got %v", err)
+	}
+}
@@ -0,0 +1,176 @@
+package unit
+
+import (
+	"errors"
+	"parking-lot-system/internal/domain"
+	"testing"
+	"time"
+)
+
+func vehicleTestLot(opts ...domain.LotOption) *domain.ParkingLot {
+	opts = append([]domain.LotOption{domain.WithSlotSizes(
+		domain.SlotBlock{Size: domain.BikeRack, Slots: 1},
+		domain.SlotBlock{Size: domain.MotorcycleSlot, Slots: 1},
+		domain.SlotBlock{Size: domain.SmallSlot, Slots: 1},
+		domain.SlotBlock{Size: domain.LargeSlot, Slots: 1},
+		domain.SlotBlock{Size: domain.OversizeSlot, Slots: 1},
+	)}, opts...)
+	return domain.NewParkingLot(5, opts...)
+}
+
+func TestSlotSize_Accepts_ShouldFollowVehicleRules(t *testing.T) {
+	tests := []struct {
+		slot    domain.SlotSize
+		vehicle domain.Vehicle
+		accepts bool
+	}{
+		{domain.BikeRack, domain.Bicycle{Tag: "B1"}, true},
+		{domain.BikeRack, domain.Motorcycle{Plate: "M1"}, false},
+		{domain.MotorcycleSlot, domain.Motorcycle{Plate: "M1"}, true},
+		{domain.MotorcycleSlot, domain.Car{Plate: "C1", Size: domain.Small}, false},
+		{domain.SmallSlot, domain.Motorcycle{Plate: "M1"}, true},
+		{domain.LargeSlot, domain.Van{Plate: "V1"}, true},
+		{domain.LargeSlot, domain.Bus{Plate: "X1"}, false},
+		{domain.AnySize, domain.Truck{Plate: "T1"}, false},
+		{domain.OversizeSlot, domain.Truck{Plate: "T1"}, true},
+	}
+
+	for _, test := range tests {
+		if got := test.slot.Accepts(test.vehicle); got != test.accepts {
+			t.Errorf("%s slot accepts %s: expected %v, got %v", test.slot, test.vehicle.GetType(), test.accepts, got)
+		}
+	}
+}
+
+func TestParkingLot_ParkVehicle_ShouldPlaceEachTypeInItsTightestSlot(t *testing.T) {
+	lot := vehicleTestLot()
+
+	vehicles := []domain.Vehicle{
+		domain.Bicycle{Tag: "BK-001", Color: "Red"},
+		domain.Motorcycle{Plate: "KA01MC0001", Make: "Honda"},
+		domain.Van{Plate: "KA01VN0001", Color: "White"},
+		domain.Bus{Plate: "KA01BS0001"},
+	}
+	for _, vehicle := range vehicles {
+		if _, err := lot.ParkVehicle(vehicle); err != nil {
+			t.Fatalf("Expected the %s to park, got %v", vehicle.GetType(), err)
+		}
+	}
+
+	expected := map[string]int{"BK-001": 0, "KA01MC0001": 1, "KA01VN0001": 3, "KA01BS0001": 4}
+	for plate, slot := range expected {
+		if lot.FindCar(plate) != slot {
+			t.Errorf("Expected %s in slot %d, got %d", plate, slot, lot.FindCar(plate))
+		}
+	}
+	if buses := lot.FindVehiclesByType(domain.VehicleBus); len(buses) != 1 || buses[0].Plate != "KA01BS0001" {
+		t.Errorf("Expected to find the bus, got %v", buses)
+	}
+}
+
+func TestParkingLot_ParkVehicle_ShouldRejectBusWithoutOversizeSlot(t *testing.T) {
+	lot := domain.NewParkingLot(4)
+
+	_, err := lot.ParkVehicle(domain.Bus{Plate: "KA01BS0001"})
+	if !errors.Is(err, domain.ErrLotFull) {
+		t.Errorf("Expected ErrLotFull for a bus in an unsized lot, got %v", err)
+	}
+	if lot.CanFitVehicle(domain.Truck{Plate: "KA01TR0001"}) || !lot.CanFitVehicle(domain.Motorcycle{Plate: "KA01MC0001"}) {
+		t.Errorf("Expected room for motorcycles but not trucks")
+	}
+}
+
+func TestParkingLot_Query_ShouldMatchVehicleType(t *testing.T) {
+	lot := vehicleTestLot()
+	lot.ParkVehicle(domain.Motorcycle{Plate: "KA01MC0001"})
+	lot.Park(domain.Car{Plate: "KA01CR0001", Size: domain.Small})
+
+	query, err := domain.ParseQuery("type = motorcycle")
+	if err != nil {
+		t.Fatalf("Expected the query to parse, got %v", err)
+	}
+	if found := lot.Query(query); len(found) != 1 || found[0].Car.Plate != "KA01MC0001" {
+		t.Errorf("Expected only the motorcycle, got %v", found)
+	}
+	if _, err := domain.ParseQuery("type = hovercraft"); err == nil {
+		t.Errorf("Expected an unknown vehicle type to be rejected")
+	}
+}
+
+func TestStandardTariff_ShouldPriceVehiclesByType(t *testing.T) {
+	clock := domain.NewFakeClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	lot := vehicleTestLot(domain.WithClock(clock), domain.WithTariff(domain.StandardTariff{
+		HourlyRates:      map[domain.CarSize]domain.Money{domain.Small: 200, domain.Large: 400},
+		VehicleRates:     map[domain.VehicleType]domain.Money{domain.VehicleBus: 1500},
+		VehicleDailyCaps: map[domain.VehicleType]domain.Money{domain.VehicleBus: 3000},
+	}))
+	bus := domain.Bus{Plate: "KA01BS0001"}
+	motorcycle := domain.Motorcycle{Plate: "KA01MC0001"}
+	lot.ParkVehicle(bus)
+	lot.ParkVehicle(motorcycle)
+	clock.Advance(3 * time.Hour)
+
+	receipt, err := lot.UnparkVehicle(bus)
+	if err != nil || receipt.Type != domain.VehicleBus || receipt.Total != 3000 {
+		t.Errorf("Expected the bus capped at 30.00, got %s for %s (%v)", receipt.Total, receipt.Type, err)
+	}
+	// Without a rate of their own, motorcycles pay the rate for their size
+	receipt, _ = lot.UnparkVehicle(motorcycle)
+	if receipt.Total != 600 {
+		t.Errorf("Expected the motorcycle to pay 6.00, got %s", receipt.Total)
+	}
+}
+
+func TestParkingAttendant_ParkVehicle_ShouldUseNearestLotWithRoom(t *testing.T) {
+	plain := domain.NewParkingLot(10)
+	depot := vehicleTestLot()
+	history := domain.NewInMemoryHistoryStore()
+	depot.SetHistoryStore(history)
+	attendant := domain.NewParkingAttendant("John Doe")
+
+	truck := domain.Truck{Plate: "KA01TR0001", Color: "White"}
+	if _, err := attendant.ParkVehicle([]*domain.ParkingLot{plain, depot}, truck); err != nil {
+		t.Fatalf("Expected the truck to park, got %v", err)
+	}
+	if depot.FindCar("KA01TR0001") != 4 {
+		t.Errorf("Expected the truck in the oversize slot, got %d", depot.FindCar("KA01TR0001"))
+	}
+
+	police := domain.NewPoliceDepartment("City Police")
+	white := police.InvestigateWhiteCars([]*domain.ParkingLot{plain, depot})
+	if len(white) != 1 || white[0].Car.Type != domain.VehicleTruck {
+		t.Errorf("Expected the white truck in the report, got %v", white)
+	}
+
+	depot.UnparkVehicle(truck)
+	trucks := domain.VehicleTruck
+	if visits := history.Query(domain.VisitFilter{Type: &trucks}); len(visits) != 1 || visits[0].Car().Type != domain.VehicleTruck {
+		t.Errorf("Expected the truck's visit in the history, got %v", visits)
+	}
+}
+
+func TestParkingLot_ParkVehicle_ShouldRejectNilVehicle(t *testing.T) {
+	lot := vehicleTestLot()
+	attendant := domain.NewParkingAttendant("John Doe")
+
+	if _, err := lot.ParkVehicle(nil); !errors.Is(err, domain.ErrInvalidCar) {
+		t.Errorf("Expected ErrInvalidCar from ParkVehicle, got %v", err)
+	}
+	if _, err := lot.UnparkVehicle(nil); !errors.Is(err, domain.ErrInvalidCar) {
+		t.Errorf("Expected ErrInvalidCar from UnparkVehicle, got %v", err)
+	}
+	if _, err := attendant.ParkVehicle([]*domain.ParkingLot{lot}, nil); !errors.Is(err, domain.ErrInvalidCar) {
+		t.Errorf("Expected ErrInvalidCar from the attendant, got %v", err)
+	}
+	if lot.CanFitVehicle(nil) || lot.CanCharge(nil) {
+		t.Errorf("Expected no room for a nil vehicle")
+	}
+	req := domain.ParkingRequest{}
+	if domain.IsElectric(req) || domain.IsLarge(req) || domain.IsVehicleType(domain.VehicleCar)(req) {
+		t.Errorf("Expected matchers not to match a nil vehicle")
+	}
+	lots := []*domain.ParkingLot{lot}
+	if domain.DefaultStrategy().ChooseLot(lots, req) != nil || (domain.BestFitStrategy{}).ChooseLot(lots, req) != nil {
+		t.Errorf("Expected strategies to choose no lot for a nil vehicle")
+	}
+}